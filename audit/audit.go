@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"encoding/json"
+	"html"
+	"log"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/pubsub"
+	"retro-chat-rooms/storage"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// Events gets every entry as it's recorded, for mirroring elsewhere.
+var Events = pubsub.NewPubsub()
+
+func Record(e Entry) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	if err := storage.Current.Append(AUDIT_LOG, e); err != nil {
+		log.Printf("Error saving audit entry: %v", err)
+	}
+
+	Events.Publish(e)
+}
+
+// RecordModerationEvents writes every moderation event to the log as it
+// happens, instead of waiting on a subscriber that could drop it.
+func RecordModerationEvents() {
+	chat.OnModerationEvent(func(evt chat.ChatModerationEvent) {
+		Record(FromModerationEvent(evt))
+	})
+}
+
+// FromModerationEvent turns a room's moderation event into an entry,
+// nicknames are kept as plain text.
+func FromModerationEvent(evt chat.ChatModerationEvent) Entry {
+	return Entry{
+		Time:     evt.Time,
+		Action:   evt.Action,
+		RoomID:   evt.RoomID,
+		Actor:    html.UnescapeString(evt.Actor),
+		Target:   html.UnescapeString(evt.Target.Nickname),
+		TargetID: evt.Target.ID,
+		TargetIP: evt.Target.IP,
+		Reason:   evt.Reason,
+	}
+}
+
+func containsFold(s string, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func (f Filter) Matches(e Entry) bool {
+	return (f.Action == "" || e.Action == f.Action) &&
+		(f.RoomID == "" || e.RoomID == f.RoomID) &&
+		(f.Actor == "" || containsFold(e.Actor, f.Actor)) &&
+		(f.Target == "" || containsFold(e.Target, f.Target) || e.TargetIP == f.Target) &&
+		(f.From.IsZero() || !e.Time.Before(f.From)) &&
+		(f.To.IsZero() || !e.Time.After(f.To))
+}
+
+// Query returns the newest entries matching the filter, newest first.
+func Query(f Filter) ([]Entry, error) {
+	entries := make([]Entry, 0)
+
+	err := storage.Current.Scan(AUDIT_LOG, func(data []byte) error {
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil
+		}
+
+		if f.Matches(e) {
+			entries = append(entries, e)
+		}
+
+		if len(entries) > MAX_QUERY_RESULTS {
+			entries = entries[1:]
+		}
+
+		return nil
+	})
+
+	return lo.Reverse(entries), err
+}
+
+// Format describes the entry in one line of plain text.
+func Format(e Entry) string {
+	var b strings.Builder
+
+	b.WriteString("[" + e.Action + "]")
+
+	if e.Actor != "" {
+		b.WriteString(" " + e.Actor)
+	}
+	if e.Target != "" {
+		b.WriteString(" -> " + e.Target)
+	}
+	if e.RoomID != "" {
+		b.WriteString(" in " + e.RoomID)
+	}
+	if e.Reason != "" {
+		b.WriteString(": " + e.Reason)
+	}
+
+	return b.String()
+}
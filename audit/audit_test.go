@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/pubsub"
+	"retro-chat-rooms/storage"
+	"testing"
+)
+
+func TestModerationIsNeverDropped(t *testing.T) {
+	storage.Current = storage.NewMemoryBackend()
+	RecordModerationEvents()
+
+	// A subscriber that never reads fills its buffer after 100 events
+	chat.RoomEvents["general"] = pubsub.NewPubsub()
+	chat.RoomEvents["general"].Subscribe("stuck")
+	Events.Subscribe("stuck")
+
+	for i := 0; i < 250; i++ {
+		chat.PublishModerationEvent(chat.ChatModerationEvent{
+			Action: chat.MODERATION_KICK,
+			RoomID: "general",
+			Actor:  "Mod",
+			Target: chat.ChatUser{Nickname: "Troll"},
+		})
+	}
+
+	entries, err := Query(Filter{Action: chat.MODERATION_KICK})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 250 {
+		t.Errorf("%d kicks in the audit log, want 250", len(entries))
+	}
+	if entries[0].Actor != "Mod" || entries[0].Target != "Troll" || entries[0].Time.IsZero() {
+		t.Errorf("recorded as %+v", entries[0])
+	}
+}
@@ -0,0 +1,42 @@
+package audit
+
+import "retro-chat-rooms/chat"
+
+const AUDIT_LOG = "audit"
+
+// Most entries the admin area shows at once
+const MAX_QUERY_RESULTS = 500
+
+// Besides chat.MODERATION_*
+const (
+	ACTION_LOGIN        = "login"
+	ACTION_LOGIN_FAILED = "login-failed"
+	ACTION_BAN_ADD      = "ban-add"
+	ACTION_BAN_LIFT     = "ban-lift"
+	// Reports that are acted on show up as the action itself
+	ACTION_REPORT_DISMISS = "report-dismiss"
+	ACTION_FILTER_ADD     = "filter-add"
+	ACTION_FILTER_REMOVE  = "filter-remove"
+	ACTION_FLOOD_RESET    = "flood-reset"
+)
+
+var ACTIONS = []string{
+	chat.MODERATION_KICK,
+	chat.MODERATION_FLOOD_KICK,
+	chat.MODERATION_BAN,
+	chat.MODERATION_MUTE,
+	chat.MODERATION_UNMUTE,
+	chat.MODERATION_PURGE,
+	chat.MODERATION_SHADOW,
+	chat.MODERATION_UNSHADOW,
+	chat.MODERATION_ROLE,
+	chat.MODERATION_ROOM_CHANGE,
+	ACTION_LOGIN,
+	ACTION_LOGIN_FAILED,
+	ACTION_BAN_ADD,
+	ACTION_BAN_LIFT,
+	ACTION_REPORT_DISMISS,
+	ACTION_FILTER_ADD,
+	ACTION_FILTER_REMOVE,
+	ACTION_FLOOD_RESET,
+}
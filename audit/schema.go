@@ -0,0 +1,25 @@
+package audit
+
+import "time"
+
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	// Empty for actions that aren't about a room
+	RoomID   string `json:"roomId,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+	Target   string `json:"target,omitempty"`
+	TargetID string `json:"targetId,omitempty"`
+	TargetIP string `json:"targetIp,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Filter narrows down a query, empty fields match everything.
+type Filter struct {
+	Action string
+	RoomID string
+	Actor  string
+	Target string
+	From   time.Time
+	To     time.Time
+}
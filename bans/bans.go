@@ -0,0 +1,254 @@
+package bans
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"path"
+	"retro-chat-rooms/netblocks"
+	"retro-chat-rooms/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+var (
+	bans []Ban = make([]Ban, 0)
+
+	loadOnce = sync.Once{}
+	mutex    = sync.Mutex{}
+)
+
+var (
+	ErrInvalidType    = errors.New("unknown ban type")
+	ErrInvalidIP      = errors.New("invalid IP address")
+	ErrInvalidCIDR    = errors.New("invalid CIDR range, use something like 10.0.0.0/8")
+	ErrInvalidPattern = errors.New("invalid nickname pattern")
+	ErrEmptyValue     = errors.New("nothing to ban")
+)
+
+// ensureLoaded reads the bans saved by a previous run, expects mutex to be locked.
+func ensureLoaded() {
+	loadOnce.Do(func() {
+		_, err := storage.Current.Load(BANS_DOCUMENT, &bans)
+		if err != nil {
+			log.Printf("Error loading bans: %v", err)
+		}
+	})
+}
+
+// save drops expired bans and writes the rest, expects mutex to be locked.
+func save() {
+	now := time.Now().UTC()
+	bans = lo.Filter(bans, func(b Ban, _ int) bool {
+		return !b.IsExpired(now)
+	})
+
+	err := storage.Current.Save(BANS_DOCUMENT, bans)
+	if err != nil {
+		log.Printf("Error saving bans: %v", err)
+	}
+}
+
+// Fingerprint identifies a web session without storing what it's made of.
+func Fingerprint(parts ...string) string {
+	hasher := sha1.New()
+	hasher.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(hasher.Sum(nil))[:16]
+}
+
+func normalize(banType string, value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return "", ErrEmptyValue
+	}
+
+	switch banType {
+	case TYPE_IP:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", ErrInvalidIP
+		}
+		return ip.String(), nil
+	case TYPE_CIDR:
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return "", ErrInvalidCIDR
+		}
+		return network.String(), nil
+	case TYPE_NICKNAME:
+		value = strings.ToLower(value)
+		if _, err := path.Match(value, ""); err != nil {
+			return "", ErrInvalidPattern
+		}
+		return value, nil
+	case TYPE_FINGERPRINT, TYPE_DISCORD:
+		return value, nil
+	}
+
+	return "", ErrInvalidType
+}
+
+func add(banType string, value string, reason string, issuedBy string, duration time.Duration, shadow bool) (Ban, error) {
+	value, err := normalize(banType, value)
+	if err != nil {
+		return Ban{}, err
+	}
+
+	now := time.Now().UTC()
+
+	ban := Ban{
+		ID:       uuid.NewString(),
+		Type:     banType,
+		Value:    value,
+		Reason:   strings.TrimSpace(reason),
+		IssuedBy: issuedBy,
+		Created:  now,
+		Shadow:   shadow,
+	}
+
+	if duration > 0 {
+		ban.Expires = now.Add(duration)
+	}
+
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	bans = append(bans, ban)
+	save()
+
+	return ban, nil
+}
+
+// Add saves a ban, duration 0 bans forever.
+func Add(banType string, value string, reason string, issuedBy string, duration time.Duration) (Ban, error) {
+	return add(banType, value, reason, issuedBy, duration, false)
+}
+
+// AddShadow saves a shadow ban, duration 0 lasts forever.
+func AddShadow(banType string, value string, reason string, issuedBy string, duration time.Duration) (Ban, error) {
+	return add(banType, value, reason, issuedBy, duration, true)
+}
+
+// Remove lifts the ban and returns it.
+func Remove(id string) (Ban, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	ban, index, found := lo.FindIndexOf(bans, func(b Ban) bool {
+		return b.ID == id
+	})
+
+	if !found {
+		return Ban{}, false
+	}
+
+	bans = append(bans[:index], bans[index+1:]...)
+	save()
+
+	return ban, true
+}
+
+// List returns the bans still in effect, newest first.
+func List() []Ban {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	now := time.Now().UTC()
+	active := lo.Filter(bans, func(b Ban, _ int) bool {
+		return !b.IsExpired(now)
+	})
+
+	return lo.Reverse(active)
+}
+
+func find(c Candidate, shadow bool) (Ban, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	now := time.Now().UTC()
+
+	return lo.Find(bans, func(b Ban) bool {
+		return b.Shadow == shadow && !b.IsExpired(now) && b.Matches(c)
+	})
+}
+
+// Check returns the first ban in effect that matches the candidate.
+func Check(c Candidate) (Ban, bool) {
+	return find(c, false)
+}
+
+// CheckShadow returns the first shadow ban in effect that matches the candidate.
+func CheckShadow(c Candidate) (Ban, bool) {
+	return find(c, true)
+}
+
+// LiftShadow removes every shadow ban matching the candidate.
+func LiftShadow(c Candidate) []Ban {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	lifted, kept := lo.FilterReject(bans, func(b Ban, _ int) bool {
+		return b.Shadow && b.Matches(c)
+	})
+
+	if len(lifted) > 0 {
+		bans = kept
+		save()
+	}
+
+	return lifted
+}
+
+func (b Ban) IsExpired(now time.Time) bool {
+	return !b.Expires.IsZero() && now.After(b.Expires)
+}
+
+func (b Ban) Matches(c Candidate) bool {
+	switch b.Type {
+	case TYPE_IP:
+		// Banning an IPv6 address bans the prefix it's in
+		return netblocks.SameGroup(c.IP, b.Value)
+	case TYPE_CIDR:
+		ip := net.ParseIP(c.IP)
+		_, network, err := net.ParseCIDR(b.Value)
+		return ip != nil && err == nil && network.Contains(ip)
+	case TYPE_NICKNAME:
+		matched, _ := path.Match(b.Value, strings.ToLower(strings.TrimSpace(c.Nickname)))
+		return c.Nickname != "" && matched
+	case TYPE_FINGERPRINT:
+		return c.Fingerprint != "" && c.Fingerprint == b.Value
+	case TYPE_DISCORD:
+		return c.DiscordId != "" && c.DiscordId == b.Value
+	}
+
+	return false
+}
+
+// Describe tells the banned user why they can't get in.
+func (b Ban) Describe() string {
+	message := "You are banned"
+
+	if b.Expires.IsZero() {
+		message += " from this chat"
+	} else {
+		message += " until " + b.Expires.Format("2006-01-02 15:04") + " UTC"
+	}
+
+	if b.Reason != "" {
+		message += ": " + b.Reason
+	}
+
+	return message + "."
+}
@@ -0,0 +1,120 @@
+package bans
+
+import (
+	"retro-chat-rooms/storage"
+	"testing"
+	"time"
+)
+
+func reset() {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	storage.Current = storage.NewMemoryBackend()
+	loadOnce.Do(func() {})
+	bans = make([]Ban, 0)
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		banType string
+		value   string
+		want    string
+		err     error
+	}{
+		{TYPE_IP, " 192.0.2.1 ", "192.0.2.1", nil},
+		{TYPE_IP, "2001:DB8::1", "2001:db8::1", nil},
+		{TYPE_IP, "192.0.2", "", ErrInvalidIP},
+		{TYPE_CIDR, "10.1.2.3/8", "10.0.0.0/8", nil},
+		{TYPE_CIDR, "2001:db8::1/32", "2001:db8::/32", nil},
+		{TYPE_CIDR, "10.0.0.0/33", "", ErrInvalidCIDR},
+		{TYPE_NICKNAME, "Troll*", "troll*", nil},
+		{TYPE_NICKNAME, "troll[", "", ErrInvalidPattern},
+		{TYPE_DISCORD, "1234", "1234", nil},
+		{TYPE_IP, "  ", "", ErrEmptyValue},
+		{"email", "a@b.c", "", ErrInvalidType},
+	}
+
+	for _, c := range cases {
+		got, err := normalize(c.banType, c.value)
+		if got != c.want || err != c.err {
+			t.Errorf("normalize(%s, %q) = %q, %v, want %q, %v", c.banType, c.value, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		name      string
+		ban       Ban
+		candidate Candidate
+		want      bool
+	}{
+		{"same IPv4", Ban{Type: TYPE_IP, Value: "192.0.2.1"}, Candidate{IP: "192.0.2.1"}, true},
+		{"other IPv4", Ban{Type: TYPE_IP, Value: "192.0.2.1"}, Candidate{IP: "192.0.2.2"}, false},
+		{"IPv4 mapped in IPv6", Ban{Type: TYPE_IP, Value: "192.0.2.1"}, Candidate{IP: "::ffff:192.0.2.1"}, true},
+		{"same IPv6 prefix", Ban{Type: TYPE_IP, Value: "2001:db8:1:2::1"}, Candidate{IP: "2001:db8:1:2:ffff::9"}, true},
+		{"other IPv6 prefix", Ban{Type: TYPE_IP, Value: "2001:db8:1:2::1"}, Candidate{IP: "2001:db8:1:3::1"}, false},
+		{"no IP", Ban{Type: TYPE_IP, Value: "192.0.2.1"}, Candidate{Nickname: "someone"}, false},
+		{"inside CIDR", Ban{Type: TYPE_CIDR, Value: "10.0.0.0/8"}, Candidate{IP: "10.200.3.4"}, true},
+		{"outside CIDR", Ban{Type: TYPE_CIDR, Value: "10.0.0.0/8"}, Candidate{IP: "11.0.0.1"}, false},
+		{"inside IPv6 CIDR", Ban{Type: TYPE_CIDR, Value: "2001:db8::/32"}, Candidate{IP: "2001:db8:ffff::1"}, true},
+		{"CIDR without IP", Ban{Type: TYPE_CIDR, Value: "10.0.0.0/8"}, Candidate{}, false},
+		{"nickname pattern", Ban{Type: TYPE_NICKNAME, Value: "troll*"}, Candidate{Nickname: " TrollFace "}, true},
+		{"nickname single character", Ban{Type: TYPE_NICKNAME, Value: "bo?"}, Candidate{Nickname: "bobby"}, false},
+		{"empty nickname", Ban{Type: TYPE_NICKNAME, Value: "*"}, Candidate{}, false},
+		{"fingerprint", Ban{Type: TYPE_FINGERPRINT, Value: "abc"}, Candidate{Fingerprint: "abc"}, true},
+		{"empty fingerprint", Ban{Type: TYPE_FINGERPRINT, Value: ""}, Candidate{}, false},
+		{"discord", Ban{Type: TYPE_DISCORD, Value: "42"}, Candidate{DiscordId: "42"}, true},
+		{"other discord", Ban{Type: TYPE_DISCORD, Value: "42"}, Candidate{DiscordId: "43"}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.ban.Matches(c.candidate); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	reset()
+
+	if _, err := Add(TYPE_CIDR, "198.51.100.0/24", "proxies", "Mod", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddShadow(TYPE_NICKNAME, "lurker", "", "Mod", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Add(TYPE_IP, "192.0.2.1", "", "Mod", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	bans[len(bans)-1].Expires = time.Now().Add(-time.Second)
+	mutex.Unlock()
+
+	cases := []struct {
+		name      string
+		candidate Candidate
+		banned    bool
+		shadow    bool
+	}{
+		{"in the banned range", Candidate{IP: "198.51.100.7"}, true, false},
+		{"shadow banned", Candidate{Nickname: "Lurker"}, false, true},
+		{"expired", Candidate{IP: "192.0.2.1"}, false, false},
+		{"nobody", Candidate{IP: "203.0.113.1", Nickname: "friend"}, false, false},
+	}
+
+	for _, c := range cases {
+		if _, banned := Check(c.candidate); banned != c.banned {
+			t.Errorf("%s: banned is %v, want %v", c.name, banned, c.banned)
+		}
+		if _, shadow := CheckShadow(c.candidate); shadow != c.shadow {
+			t.Errorf("%s: shadow banned is %v, want %v", c.name, shadow, c.shadow)
+		}
+	}
+
+	if active := List(); len(active) != 2 || active[0].Type != TYPE_NICKNAME {
+		t.Errorf("List should have the two bans in effect newest first, got %v", active)
+	}
+}
@@ -0,0 +1,14 @@
+package bans
+
+const BANS_DOCUMENT = "bans"
+
+const (
+	TYPE_IP       = "ip"
+	TYPE_CIDR     = "cidr"
+	TYPE_NICKNAME = "nickname"
+	// Web session, see Fingerprint
+	TYPE_FINGERPRINT = "fingerprint"
+	TYPE_DISCORD     = "discord"
+)
+
+var TYPES = []string{TYPE_IP, TYPE_CIDR, TYPE_NICKNAME, TYPE_FINGERPRINT, TYPE_DISCORD}
@@ -0,0 +1,26 @@
+package bans
+
+import "time"
+
+type Ban struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// IP, CIDR range, nickname pattern (* and ? wildcards),
+	// fingerprint or Discord ID depending on the type
+	Value    string    `json:"value"`
+	Reason   string    `json:"reason"`
+	IssuedBy string    `json:"issuedBy"`
+	Created  time.Time `json:"created"`
+	// Zero for bans that never expire
+	Expires time.Time `json:"expires"`
+	// Shadow bans let the user in, but nobody sees what they say
+	Shadow bool `json:"shadow,omitempty"`
+}
+
+// Candidate is who is trying to get in, empty fields aren't checked.
+type Candidate struct {
+	IP          string
+	Nickname    string
+	Fingerprint string
+	DiscordId   string
+}
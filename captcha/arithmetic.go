@@ -0,0 +1,28 @@
+package captcha
+
+import (
+	"log"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+type arithmetic struct{}
+
+func getWestCoastDayOfMonth() int {
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		log.Printf("Error loading timezone: %v", err)
+		return time.Now().Day()
+	}
+	return time.Now().In(loc).Day()
+}
+
+func (arithmetic) New() Challenge {
+	a := rand.Intn(8999) + 1000
+
+	return Challenge{
+		Prompt:  "Please enter the sum of " + strconv.Itoa(a) + " plus the current day of the month in the west coast of 'murica",
+		answers: []string{strconv.Itoa(a + getWestCoastDayOfMonth())},
+	}
+}
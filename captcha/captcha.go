@@ -0,0 +1,166 @@
+package captcha
+
+import (
+	"log"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/netblocks"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// Captcha makes challenges of one kind.
+type Captcha interface {
+	// New returns a challenge with its prompt and answers set
+	New() Challenge
+}
+
+var (
+	mutex   sync.Mutex
+	pending = map[string]Challenge{}
+	// IDs in the order they were issued, to drop the oldest
+	order = []string{}
+	// IDs for each IP group, oldest first
+	byGroup = map[string][]string{}
+
+	captchas = map[string]Captcha{
+		KIND_ARITHMETIC: arithmetic{},
+		KIND_IMAGE:      distortedText{},
+		KIND_QUESTIONS:  questions{},
+	}
+)
+
+func byKind(kind string, fallback string) Captcha {
+	if kind == "" {
+		kind = fallback
+	}
+
+	c, found := captchas[kind]
+	if !found {
+		log.Printf("Unknown captcha %q, using %q", kind, fallback)
+		return captchas[fallback]
+	}
+	return c
+}
+
+// ForRoom returns the captcha people solve to join the room.
+func ForRoom(roomId string) Captcha {
+	room, _ := lo.Find(config.Current.Rooms, func(r config.ConfigChatRoom) bool { return r.ID == roomId })
+	return byKind(room.Captcha, DEFAULT_ROOM_KIND)
+}
+
+// ForAdmin returns the captcha moderators solve to log in.
+func ForAdmin() Captcha {
+	return byKind(config.Current.AdminCaptcha, DEFAULT_ADMIN_KIND)
+}
+
+// forget drops the challenge, expects mutex to be locked.
+func forget(id string) {
+	challenge, found := pending[id]
+	if !found {
+		return
+	}
+
+	delete(pending, id)
+
+	ids := lo.Without(byGroup[challenge.group], id)
+	if len(ids) == 0 {
+		delete(byGroup, challenge.group)
+	} else {
+		byGroup[challenge.group] = ids
+	}
+}
+
+func dropExpired() {
+	now := time.Now().UTC()
+
+	for len(order) > 0 {
+		oldest, found := pending[order[0]]
+		if found && now.Before(oldest.expires) && len(order) <= MAX_PENDING {
+			return
+		}
+		forget(order[0])
+		order = order[1:]
+	}
+}
+
+// Issue gives the IP a challenge to solve, current is the one it got
+// last time, which is given again while it's still good so reloading
+// the page doesn't pile them up.
+func Issue(c Captcha, ip string, current string) Challenge {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	now := time.Now().UTC()
+
+	if challenge, found := pending[current]; found && challenge.source == c && now.Before(challenge.expires) {
+		return challenge
+	}
+
+	challenge := c.New()
+	challenge.ID = uuid.NewString()
+	challenge.expires = now.Add(CHALLENGE_TTL_MIN * time.Minute)
+	challenge.group = netblocks.GroupKey(ip)
+	challenge.source = c
+
+	// The IP's own oldest ones make room, never someone else's
+	for len(byGroup[challenge.group]) >= MAX_PENDING_PER_IP {
+		forget(byGroup[challenge.group][0])
+	}
+
+	pending[challenge.ID] = challenge
+	order = append(order, challenge.ID)
+	byGroup[challenge.group] = append(byGroup[challenge.group], challenge.ID)
+	dropExpired()
+
+	return challenge
+}
+
+func normalizeAnswer(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(answer)), " ")
+}
+
+// Check tells if the input answers the challenge, each challenge can
+// only be tried once.
+func Check(id string, input string) bool {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	challenge, found := pending[id]
+	if !found || time.Now().UTC().After(challenge.expires) {
+		return false
+	}
+
+	forget(id)
+
+	return lo.ContainsBy(challenge.answers, func(answer string) bool {
+		return normalizeAnswer(answer) == normalizeAnswer(input)
+	})
+}
+
+// Image returns the GIF for the challenge, if it has one.
+func Image(id string) ([]byte, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	challenge, found := pending[id]
+	if !found || !challenge.HasImage {
+		return nil, false
+	}
+
+	if challenge.image == nil {
+		data, err := renderGif(challenge.text)
+		if err != nil {
+			log.Printf("Error rendering captcha: %v", err)
+			return nil, false
+		}
+
+		challenge.image = data
+		pending[id] = challenge
+	}
+
+	return challenge.image, true
+}
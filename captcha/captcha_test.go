@@ -0,0 +1,151 @@
+package captcha
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fixedCaptcha struct{}
+
+func (fixedCaptcha) New() Challenge {
+	return Challenge{Prompt: "What do you save files on?", answers: []string{"Floppy  Disk", "diskette"}}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"exact", "Floppy  Disk", true},
+		{"case and spaces", "  floppy disk ", true},
+		{"second answer", "DISKETTE", true},
+		{"wrong", "cd-rom", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge := Issue(fixedCaptcha{}, "192.0.2.1", "")
+			if got := Check(challenge.ID, tt.input); got != tt.want {
+				t.Errorf("Check(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOnlyOnce(t *testing.T) {
+	challenge := Issue(fixedCaptcha{}, "192.0.2.2", "")
+
+	if Check(challenge.ID, "cd-rom") {
+		t.Fatal("a wrong answer passed")
+	}
+	if Check(challenge.ID, "diskette") {
+		t.Error("the challenge could be tried again after a wrong answer")
+	}
+
+	challenge = Issue(fixedCaptcha{}, "192.0.2.2", "")
+	if !Check(challenge.ID, "diskette") {
+		t.Fatal("the right answer didn't pass")
+	}
+	if Check(challenge.ID, "diskette") {
+		t.Error("a solved challenge passed again")
+	}
+
+	if Check("unknown", "diskette") || Check("", "") {
+		t.Error("a challenge that was never issued passed")
+	}
+}
+
+func TestCheckExpired(t *testing.T) {
+	challenge := Issue(fixedCaptcha{}, "192.0.2.3", "")
+
+	mutex.Lock()
+	expired := pending[challenge.ID]
+	expired.expires = time.Now().UTC().Add(-time.Second)
+	pending[challenge.ID] = expired
+	mutex.Unlock()
+
+	if Check(challenge.ID, "diskette") {
+		t.Error("an expired challenge passed")
+	}
+}
+
+func TestIssueReusesCurrent(t *testing.T) {
+	first := Issue(fixedCaptcha{}, "192.0.2.4", "")
+
+	if again := Issue(fixedCaptcha{}, "192.0.2.4", first.ID); again.ID != first.ID {
+		t.Error("reloading the page gave a new challenge")
+	}
+
+	if other := Issue(arithmetic{}, "192.0.2.4", first.ID); other.ID == first.ID {
+		t.Error("a different kind of captcha reused the challenge")
+	}
+
+	Check(first.ID, "")
+	if again := Issue(fixedCaptcha{}, "192.0.2.4", first.ID); again.ID == first.ID {
+		t.Error("a used challenge was given again")
+	}
+}
+
+func TestIssuePerIPLimit(t *testing.T) {
+	victim := Issue(fixedCaptcha{}, "198.51.100.1", "")
+
+	first := Issue(fixedCaptcha{}, "203.0.113.1", "")
+	for i := 0; i < MAX_PENDING_PER_IP*3; i++ {
+		Issue(fixedCaptcha{}, "203.0.113.1", "")
+	}
+
+	mutex.Lock()
+	count := len(byGroup["203.0.113.1"])
+	mutex.Unlock()
+
+	if count != MAX_PENDING_PER_IP {
+		t.Errorf("the IP has %d challenges, want %d", count, MAX_PENDING_PER_IP)
+	}
+	if Check(first.ID, "diskette") {
+		t.Error("the IP's oldest challenge wasn't dropped")
+	}
+	if !Check(victim.ID, "diskette") {
+		t.Error("another IP's challenge was dropped")
+	}
+}
+
+func TestIssuePerIPv6Prefix(t *testing.T) {
+	victim := Issue(fixedCaptcha{}, "2001:db8:2::1", "")
+
+	for i := 0; i < MAX_PENDING_PER_IP*2; i++ {
+		Issue(fixedCaptcha{}, fmt.Sprintf("2001:db8:1::%x", i), "")
+	}
+
+	mutex.Lock()
+	count := len(byGroup["2001:db8:1::/64"])
+	mutex.Unlock()
+
+	if count != MAX_PENDING_PER_IP {
+		t.Errorf("the /64 has %d challenges, want %d", count, MAX_PENDING_PER_IP)
+	}
+	if !Check(victim.ID, "diskette") {
+		t.Error("another prefix's challenge was dropped")
+	}
+}
+
+func TestImageCached(t *testing.T) {
+	challenge := Issue(distortedText{}, "192.0.2.5", "")
+
+	first, found := Image(challenge.ID)
+	if !found || !bytes.HasPrefix(first, []byte("GIF87a")) {
+		t.Fatalf("Image() = %d bytes, %v, want a GIF", len(first), found)
+	}
+
+	second, _ := Image(challenge.ID)
+	if !bytes.Equal(first, second) {
+		t.Error("the image was drawn again")
+	}
+
+	if _, found := Image(Issue(fixedCaptcha{}, "192.0.2.5", "").ID); found {
+		t.Error("a captcha without a picture has an image")
+	}
+}
@@ -0,0 +1,35 @@
+package captcha
+
+const (
+	// "Enter the sum of 1234 plus the day of the month", the original one
+	KIND_ARITHMETIC = "arithmetic"
+	// Distorted letters in a GIF
+	KIND_IMAGE = "image"
+	// Questions from the config, or the built in ones
+	KIND_QUESTIONS = "questions"
+)
+
+var KINDS = []string{KIND_ARITHMETIC, KIND_IMAGE, KIND_QUESTIONS}
+
+const (
+	DEFAULT_ROOM_KIND  = KIND_ARITHMETIC
+	DEFAULT_ADMIN_KIND = KIND_IMAGE
+)
+
+// Unanswered challenges are forgotten after this
+const CHALLENGE_TTL_MIN = 15
+
+// Challenges kept at once, the oldest go first
+const MAX_PENDING = 10000
+
+// Challenges kept for one IP, or IPv6 prefix, its oldest go first so
+// nobody can push out everyone else's
+const MAX_PENDING_PER_IP = 10
+
+const (
+	IMAGE_WIDTH  = 200
+	IMAGE_HEIGHT = 70
+	IMAGE_LENGTH = 6
+	// Each font pixel is this many image pixels
+	IMAGE_SCALE = 4
+)
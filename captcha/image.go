@@ -0,0 +1,116 @@
+package captcha
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"math"
+	"math/rand"
+
+	"github.com/samber/lo"
+)
+
+type distortedText struct{}
+
+var alphabet = lo.Keys(glyphs)
+
+var palette = color.Palette{
+	color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	// Noise
+	color.RGBA{0xC0, 0xC0, 0xC0, 0xFF},
+	color.RGBA{0x99, 0xCC, 0xFF, 0xFF},
+	color.RGBA{0xFF, 0xCC, 0x99, 0xFF},
+	// Letters
+	color.RGBA{0x00, 0x00, 0x80, 0xFF},
+	color.RGBA{0x80, 0x00, 0x00, 0xFF},
+	color.RGBA{0x00, 0x66, 0x00, 0xFF},
+	color.RGBA{0x33, 0x33, 0x33, 0xFF},
+}
+
+const firstLetterColor = 4
+
+func (distortedText) New() Challenge {
+	text := make([]rune, IMAGE_LENGTH)
+	for i := range text {
+		text[i] = alphabet[rand.Intn(len(alphabet))]
+	}
+
+	return Challenge{
+		Prompt:   "Type the letters in the picture",
+		HasImage: true,
+		answers:  []string{string(text)},
+		text:     string(text),
+	}
+}
+
+func drawNoise(img *image.Paletted) {
+	for i := 0; i < IMAGE_WIDTH*IMAGE_HEIGHT/15; i++ {
+		img.SetColorIndex(rand.Intn(IMAGE_WIDTH), rand.Intn(IMAGE_HEIGHT), uint8(1+rand.Intn(firstLetterColor-1)))
+	}
+
+	// A few lines through the letters, in their colors so they can't
+	// just be filtered out
+	for i := 0; i < 4; i++ {
+		x0, y0 := 0.0, float64(rand.Intn(IMAGE_HEIGHT))
+		slope := (rand.Float64() - 0.5) * 0.8
+		colorIndex := uint8(firstLetterColor + rand.Intn(len(palette)-firstLetterColor))
+
+		for x := x0; x < IMAGE_WIDTH; x++ {
+			img.SetColorIndex(int(x), int(y0+slope*x), colorIndex)
+		}
+	}
+}
+
+func drawText(img *image.Paletted, text string) {
+	// The whole picture waves, each letter is shifted and leaned on its own
+	amplitude := 2 + rand.Float64()*2
+	frequency := 0.05 + rand.Float64()*0.05
+	phase := rand.Float64() * math.Pi * 2
+
+	step := (IMAGE_WIDTH - 10) / len([]rune(text))
+
+	for i, r := range []rune(text) {
+		glyph := glyphs[r]
+		left := 8 + i*step + rand.Intn(5)
+		top := 8 + rand.Intn(IMAGE_HEIGHT-GLYPH_HEIGHT*IMAGE_SCALE-14)
+		lean := (rand.Float64() - 0.5) * 0.6
+		colorIndex := uint8(firstLetterColor + rand.Intn(len(palette)-firstLetterColor))
+
+		for gy, row := range glyph {
+			for gx, cell := range row {
+				if cell != '#' {
+					continue
+				}
+
+				for sy := 0; sy < IMAGE_SCALE; sy++ {
+					for sx := 0; sx < IMAGE_SCALE; sx++ {
+						y := float64(top + gy*IMAGE_SCALE + sy)
+						x := float64(left+gx*IMAGE_SCALE+sx) + lean*(y-float64(top))
+						y += amplitude * math.Sin(x*frequency+phase)
+
+						img.SetColorIndex(int(x), int(y), colorIndex)
+					}
+				}
+			}
+		}
+	}
+}
+
+// renderGif draws the text as a GIF old browsers can show, Mosaic only
+// knows GIF87a and nothing here needs the newer one.
+func renderGif(text string) ([]byte, error) {
+	img := image.NewPaletted(image.Rect(0, 0, IMAGE_WIDTH, IMAGE_HEIGHT), palette)
+
+	drawNoise(img)
+	drawText(img, text)
+
+	var buffer bytes.Buffer
+	if err := gif.Encode(&buffer, img, &gif.Options{NumColors: len(palette)}); err != nil {
+		return nil, err
+	}
+
+	data := buffer.Bytes()
+	copy(data, "GIF87a")
+	return data, nil
+}
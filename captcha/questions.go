@@ -0,0 +1,39 @@
+package captcha
+
+import (
+	"math/rand"
+	"retro-chat-rooms/config"
+
+	"github.com/samber/lo"
+)
+
+type questions struct{}
+
+// Used when the config has no questions
+var defaultQuestions = []Question{
+	{"What do you call the 3.5 inch disk you save files on?", []string{"floppy", "floppy disk", "diskette", "floppies"}},
+	{"What makes the screeching noise when you go online with a phone line?", []string{"modem", "a modem", "the modem", "dial-up modem"}},
+	{"How many bits are in a byte?", []string{"8", "eight"}},
+	{"Which company made Windows 95?", []string{"microsoft", "microsoft corporation"}},
+	{"What color is the sky on a clear day?", []string{"blue"}},
+}
+
+func getQuestions() []Question {
+	if len(config.Current.CaptchaQuestions) == 0 {
+		return defaultQuestions
+	}
+
+	return lo.Map(config.Current.CaptchaQuestions, func(q config.CaptchaQuestionConfig, _ int) Question {
+		return Question{Question: q.Question, Answers: q.Answers}
+	})
+}
+
+func (questions) New() Challenge {
+	bank := getQuestions()
+	question := bank[rand.Intn(len(bank))]
+
+	return Challenge{
+		Prompt:  question.Question,
+		answers: question.Answers,
+	}
+}
@@ -0,0 +1,29 @@
+package captcha
+
+import "time"
+
+// Challenge is one captcha someone has to solve, the answer never
+// leaves the server.
+type Challenge struct {
+	ID string
+	// Shown next to the answer field
+	Prompt string
+	// Whether there's a picture to show, see Image
+	HasImage bool
+
+	answers []string
+	// What the picture says
+	text string
+	// Rendered the first time it's asked for
+	image   []byte
+	expires time.Time
+	// Who it was issued to and by which captcha
+	group  string
+	source Captcha
+}
+
+// Question is one entry of the question bank.
+type Question struct {
+	Question string
+	Answers  []string
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lucasb-eyer/go-colorful"
 	"github.com/samber/lo"
 )
@@ -36,9 +37,14 @@ var (
 	userLastUserListChange map[string]time.Time = make(map[string]time.Time)
 	roomLastUserListChange map[string]time.Time = make(map[string]time.Time)
 	userPings              map[string]time.Time = make(map[string]time.Time)
+	userLastActivity       map[string]time.Time = make(map[string]time.Time)
 
 	RoomEvents map[string]pubsub.Pubsub = make(map[string]pubsub.Pubsub)
 
+	// Called before a moderation event is published, these must not be
+	// skipped like a slow subscriber can be.
+	moderationRecorders []func(ChatModerationEvent)
+
 	mutex = sync.Mutex{}
 )
 
@@ -85,12 +91,18 @@ func instantiateUser(user ChatUser) error {
 		}
 	}
 
+	if isShadowBanned(user) {
+		user.Shadowed = true
+	}
+
 	users[user.ID] = user
 
 	userMessages[user.ID] = make([]*ChatMessage, 0)
 	roomUsers[user.RoomId] = append(roomUsers[user.RoomId], user.ID)
 	userLastUserListChange[user.ID] = now
+	userLastSettingsChange[user.ID] = now
 	userPings[user.ID] = now
+	userLastActivity[user.ID] = now
 
 	return nil
 }
@@ -114,9 +126,13 @@ func removeUser(combinedId string) ChatUser {
 	}
 
 	delete(userMessages, combinedId)
+	delete(userDirectMessages, combinedId)
+	delete(userDirectMessagesRead, combinedId)
 	delete(users, combinedId)
 	delete(userLastUserListChange, combinedId)
+	delete(userLastSettingsChange, combinedId)
 	delete(userPings, combinedId)
+	delete(userLastActivity, combinedId)
 
 	return user
 }
@@ -125,7 +141,7 @@ func GetCombinedId(roomId string, userId string) string {
 	return helpers.GenerateUniqueID(roomId + userId)
 }
 
-func RegisterAdmin(roomId string, clientInfo ClientInfo) string {
+func RegisterAdmin(roomId string, clientInfo ClientInfo, ip string) string {
 	cfg := config.Current.OwnerChatUser
 
 	combinedId := helpers.GenerateUniqueID(roomId + cfg.Id)
@@ -142,14 +158,47 @@ func RegisterAdmin(roomId string, clientInfo ClientInfo) string {
 		Color:     cfg.Color,
 		DiscordId: cfg.DiscordId,
 		IsAdmin:   true,
+		Role:      ROLE_OWNER,
 		RoomId:    roomId,
 		Client:    clientInfo,
+		IP:        ip,
 	})
 
 	return combinedId
 }
 
+// RegisterStaff signs in a moderator from the config, their ID is
+// always the same so they can't end up in a room twice.
+func RegisterStaff(user ChatUser) string {
+	if _, found := GetUser(user.ID); found {
+		return user.ID
+	}
+
+	RegisterUser(user)
+
+	return user.ID
+}
+
+// SetUserRole changes the role of someone who is online.
+func SetUserRole(combinedId string, role string) (ChatUser, bool) {
+	mutex.Lock()
+	user, found := users[combinedId]
+	if found {
+		user.Role = role
+		users[combinedId] = user
+	}
+	mutex.Unlock()
+
+	if found {
+		userListUpdated(user.RoomId, ChatUserUpdatedEvent{User: user})
+	}
+
+	return user, found
+}
+
 func InitializeRooms() {
+	loadMemos()
+
 	for _, cr := range config.Current.Rooms {
 		room := ChatRoom{
 			ID:                 cr.ID,
@@ -171,7 +220,7 @@ func InitializeRooms() {
 		if config.Current.OwnerChatUser.DiscordId != "" && room.DiscordChannel != "" {
 			// TODO: This could be from any client, we
 			// need to determine that on login
-			RegisterAdmin(room.ID, ClientInfo{})
+			RegisterAdmin(room.ID, ClientInfo{}, "")
 		}
 	}
 }
@@ -287,7 +336,7 @@ func RegisterUser(user ChatUser) (string, error) {
 		SendMessage(&ChatMessage{
 			RoomID:               user.RoomId,
 			Time:                 time.Now().UTC(),
-			Message:              "{nickname} has joined the room!",
+			Message:              MESSAGE_USER_JOINED,
 			IsSystemMessage:      true,
 			SystemMessageSubject: &user,
 			Privately:            false,
@@ -316,6 +365,8 @@ func RegisterUser(user ChatUser) (string, error) {
 		}
 
 		userListUpdated(user.RoomId, ChatUserJoinedEvent{User: user})
+
+		deliverMemos(user)
 	}
 
 	return user.ID, nil
@@ -328,7 +379,7 @@ func DeregisterUser(combinedId string) {
 		SendMessage(&ChatMessage{
 			RoomID:               user.RoomId,
 			Time:                 time.Now().UTC(),
-			Message:              "{nickname} has left the room!",
+			Message:              MESSAGE_USER_LEFT,
 			IsSystemMessage:      true,
 			SystemMessageSubject: &user,
 			Privately:            false,
@@ -348,17 +399,33 @@ func SendMessage(message *ChatMessage) {
 	defer mutex.Unlock()
 	mutex.Lock()
 
+	if message.ID == "" {
+		message.ID = uuid.NewString()
+	}
+
+	if !message.IsSystemMessage && message.From != "" {
+		userLastActivity[message.From] = time.Now().UTC()
+	}
+
+	if !message.IsSystemMessage && users[message.From].Shadowed {
+		message.Shadowed = true
+	}
+
 	for _, combinedId := range roomUsers[message.RoomID] {
 		if message.Privately && message.To != "" && (message.To != combinedId && message.From != combinedId) {
 			continue
 		}
 
+		if message.Shadowed && message.From != combinedId && !canSeeShadowed(users[combinedId]) {
+			continue
+		}
+
 		userMessages[combinedId] = append(userMessages[combinedId], message)
 	}
 
 	// Keeps a brief history of the public messages in the room so new people who login
 	// See some activity on the chat.
-	if !message.Privately || message.To == "" {
+	if (!message.Privately || message.To == "") && !message.Shadowed {
 		messages := roomMessageHistory[message.RoomID]
 		if len(roomMessageHistory[message.RoomID]) >= MAX_ROOM_MESSAGE_HISTORY {
 			initial := len(messages) - MAX_ROOM_MESSAGE_HISTORY + 1
@@ -371,6 +438,45 @@ func SendMessage(message *ChatMessage) {
 	RoomEvents[message.RoomID].Publish(ChatMessageEvent{Message: message})
 }
 
+// SendSystemNotice sends a system message that only the given user can see.
+func SendSystemNotice(user ChatUser, message string) {
+	SendMessage(&ChatMessage{
+		RoomID:               user.RoomId,
+		Time:                 time.Now().UTC(),
+		Message:              message,
+		IsSystemMessage:      true,
+		SystemMessageSubject: &user,
+		Privately:            true,
+		SpeechMode:           MODE_SAY_TO,
+		From:                 user.ID,
+		To:                   user.ID,
+		ShowClientIcon:       false,
+		InvolvedUsers:        []ChatUser{user},
+	})
+}
+
+// OnModerationEvent registers a recorder that every moderation event is
+// handed to synchronously, before it's published. Call it at startup.
+func OnModerationEvent(recorder func(ChatModerationEvent)) {
+	moderationRecorders = append(moderationRecorders, recorder)
+}
+
+// PublishModerationEvent records a moderation action and lets everyone
+// observing the room know about it.
+func PublishModerationEvent(evt ChatModerationEvent) {
+	if evt.Time.IsZero() {
+		evt.Time = time.Now().UTC()
+	}
+
+	for _, record := range moderationRecorders {
+		record(evt)
+	}
+
+	if events, found := RoomEvents[evt.RoomID]; found {
+		events.Publish(evt)
+	}
+}
+
 func Ping(combinedId string) {
 	defer mutex.Unlock()
 	mutex.Lock()
@@ -395,6 +501,19 @@ func IsUserStale(combinedId string) bool {
 	return time.Now().UTC().Sub(lastPing).Seconds() > USER_STALE_TIMEOUT
 }
 
+// GetUserIdleTime returns how long since the user last said something.
+func GetUserIdleTime(combinedId string) time.Duration {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	lastActivity, found := userLastActivity[combinedId]
+	if !found {
+		return 0
+	}
+
+	return time.Now().UTC().Sub(lastActivity)
+}
+
 func HasUserListChanged(combinedId string) bool {
 	defer mutex.Unlock()
 	mutex.Lock()
@@ -8,6 +8,30 @@ const (
 	USER_SCREAM_TIMEOUT_MIN   float64 = 2
 	UPDATER_WAIT_TIMEOUT_MS           = 30000
 	MAX_ROOM_MESSAGE_HISTORY          = 10
+	QUOTE_EXCERPT_LENGTH              = 60
+	// How long a kicked web user can still see why they were kicked
+	KICK_NOTICE_TIMEOUT_MIN = 10
+	MAX_SLOW_MODE_SEC       = 600
+
+	MEMO_MAX_LENGTH                = 300
+	MEMO_DEFAULT_EXPIRY_DAYS       = 14
+	MEMO_DEFAULT_MAX_PER_SENDER    = 5
+	MEMO_DEFAULT_MAX_PER_RECIPIENT = 10
+
+	MESSAGE_USER_JOINED = "{nickname} has joined the room!"
+	MESSAGE_USER_LEFT   = "{nickname} has left the room!"
+
+	MODERATION_FLOOD_KICK = "flood-kick"
+	MODERATION_KICK       = "kick"
+	MODERATION_BAN        = "ban"
+	MODERATION_MUTE       = "mute"
+	MODERATION_UNMUTE     = "unmute"
+	MODERATION_PURGE      = "purge"
+	MODERATION_ROLE       = "role"
+	MODERATION_SHADOW     = "shadow-ban"
+	MODERATION_UNSHADOW   = "shadow-lift"
+	// Room settings like slow mode
+	MODERATION_ROOM_CHANGE = "room-change"
 
 	MODE_SAY_TO     = "says-to"
 	MODE_SCREAM_AT  = "screams-at"
@@ -51,3 +75,10 @@ var NICKNAME_COLORS = []NicknameColor{
 	{Color: USER_COLOR_RED, Name: "Red"},
 	{Color: USER_COLOR_BLUE, Name: "Blue"},
 }
+
+const (
+	ROLE_OWNER            = "owner"
+	ROLE_GLOBAL_MODERATOR = "global-moderator"
+	ROLE_ROOM_OPERATOR    = "room-operator"
+	ROLE_VOICED           = "voiced"
+)
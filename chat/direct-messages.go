@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"html/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+var (
+	// Key/Value list of direct messages per user, sent and received
+	userDirectMessages map[string][]*DirectMessage = make(map[string][]*DirectMessage)
+
+	// Last time the user looked at their direct messages
+	userDirectMessagesRead map[string]time.Time = make(map[string]time.Time)
+)
+
+// SendDirectMessage delivers a message between two users,
+// regardless of the room they are in.
+func SendDirectMessage(from ChatUser, to ChatUser, message string) *DirectMessage {
+	dm := &DirectMessage{
+		ID:   uuid.NewString(),
+		Time: time.Now().UTC(),
+		From: from,
+		To:   to,
+		// Same as nicknames, this ends up in the HTML pages
+		Message: template.HTMLEscapeString(message),
+	}
+
+	mutex.Lock()
+	dm.Shadowed = users[from.ID].Shadowed
+	userDirectMessages[from.ID] = appendDirectMessage(userDirectMessages[from.ID], dm)
+	if to.ID != from.ID && !dm.Shadowed {
+		userDirectMessages[to.ID] = appendDirectMessage(userDirectMessages[to.ID], dm)
+	}
+	mutex.Unlock()
+
+	// Both sides need to know, so it goes to both rooms
+	for _, roomId := range lo.Uniq([]string{from.RoomId, to.RoomId}) {
+		if events, found := RoomEvents[roomId]; found {
+			events.Publish(DirectMessageEvent{Message: dm})
+		}
+	}
+
+	return dm
+}
+
+func appendDirectMessage(messages []*DirectMessage, dm *DirectMessage) []*DirectMessage {
+	if len(messages) >= MAX_MESSAGES {
+		messages = messages[len(messages)-MAX_MESSAGES+1:]
+	}
+	return append(messages, dm)
+}
+
+func GetDirectMessages(combinedId string) []*DirectMessage {
+	defer mutex.Unlock()
+	mutex.Lock()
+	return append([]*DirectMessage{}, userDirectMessages[combinedId]...)
+}
+
+func MarkDirectMessagesRead(combinedId string) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	userDirectMessagesRead[combinedId] = time.Now().UTC()
+}
+
+// HasUnreadDirectMessages tells if someone sent the user a direct message
+// since the last time they looked at them.
+func HasUnreadDirectMessages(combinedId string) bool {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	lastRead := userDirectMessagesRead[combinedId]
+
+	return lo.SomeBy(userDirectMessages[combinedId], func(dm *DirectMessage) bool {
+		return dm.To.ID == combinedId && dm.From.ID != combinedId && dm.Time.After(lastRead)
+	})
+}
@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"html"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/helpers"
+	"strings"
+	"unicode"
+)
+
+// Characters that pass for letters in a nickname
+var nicknameLookAlikes = map[rune]rune{
+	'0': 'o', '1': 'l', 'i': 'l', '|': 'l', '!': 'l', '3': 'e', '4': 'a', '5': 's',
+	'7': 't', '8': 'b', '9': 'g', '$': 's', '@': 'a',
+}
+
+// Letter pairs that look like a single letter
+var nicknamePairs = strings.NewReplacer("rn", "m", "vv", "w")
+
+// Shorter nicknames would make everything look like them
+const MIN_PROTECTED_NICKNAME_LENGTH = 3
+
+// ProtectedNickname is someone nobody else should be able to pass for.
+type ProtectedNickname struct {
+	Nickname string
+	// Who they are, ex: "a moderator"
+	Who string
+}
+
+// foldNickname brings look-alike nicknames down to the same string,
+// ex: "Ｊ0hn_Dое" and "john doe" are both "jolmdoe".
+func foldNickname(nickname string) string {
+	folded := []rune{}
+	buffer := []rune{}
+
+	for _, r := range html.UnescapeString(nickname) {
+		buffer = helpers.FoldRune(r, buffer[:0])
+		for _, f := range buffer {
+			if look, found := nicknameLookAlikes[f]; found {
+				f = look
+			}
+			if unicode.IsLetter(f) || unicode.IsDigit(f) {
+				folded = append(folded, f)
+			}
+		}
+	}
+
+	return nicknamePairs.Replace(string(folded))
+}
+
+// getProtectedNicknames lists the owner, the moderators, the names
+// reserved in the config and whoever is talking from Discord right now.
+func getProtectedNicknames() []ProtectedNickname {
+	protected := []ProtectedNickname{
+		{Nickname: config.Current.OwnerChatUser.Nickname, Who: "who runs this place"},
+	}
+
+	for _, moderator := range config.Current.Moderators {
+		protected = append(protected, ProtectedNickname{Nickname: moderator.Nickname, Who: "a moderator"})
+	}
+
+	for _, nickname := range config.Current.ProtectedNicknames {
+		protected = append(protected, ProtectedNickname{Nickname: nickname, Who: "which is reserved"})
+	}
+
+	for _, user := range GetAllUsers() {
+		if user.IsDiscordUser() {
+			protected = append(protected, ProtectedNickname{
+				Nickname: html.UnescapeString(user.Nickname),
+				Who:      "who is here from Discord",
+			})
+		}
+	}
+
+	return protected
+}
+
+// FindImpersonated returns who the nickname could be mistaken for.
+func FindImpersonated(nickname string) (ProtectedNickname, bool) {
+	folded := foldNickname(nickname)
+	if folded == "" {
+		return ProtectedNickname{}, false
+	}
+
+	for _, protected := range getProtectedNicknames() {
+		against := foldNickname(protected.Nickname)
+		if len(against) < MIN_PROTECTED_NICKNAME_LENGTH {
+			continue
+		}
+
+		if folded == against || IsNickVariation(folded, against) {
+			return protected, true
+		}
+	}
+
+	return ProtectedNickname{}, false
+}
@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"retro-chat-rooms/config"
+	"testing"
+)
+
+func TestFindImpersonated(t *testing.T) {
+	config.Current.OwnerChatUser.Nickname = "Webmaster"
+	config.Current.Moderators = []config.ModeratorConfig{{Nickname: "Nell"}, {Nickname: "Al"}}
+	config.Current.ProtectedNicknames = []string{"Retro Bot"}
+
+	users["discord-dan"] = ChatUser{ID: "discord-dan", Nickname: "Discord Dan", DiscordId: "1234"}
+	defer delete(users, "discord-dan")
+
+	tests := []struct {
+		nickname string
+		want     string
+	}{
+		{"Webmaster", "Webmaster"},
+		{"webmaster", "Webmaster"},
+		{"Web_Master", "Webmaster"},
+		{"Web-Master", "Webmaster"},
+		{"W3bmaster", "Webmaster"},
+		{"Webrnaster", "Webmaster"},
+		{"NeIl", "Nell"},
+		{"Ne1l", "Nell"},
+		{"N e l l", "Nell"},
+		{"RetroBot", "Retro Bot"},
+		{"Retr0 B0t", "Retro Bot"},
+		// Other scripts and fullwidth letters
+		{"Wеbmastеr", "Webmaster"},
+		{"Ｗｅｂｍａｓｔｅｒ", "Webmaster"},
+		{"Wébmaster", "Webmaster"},
+		// Near variations
+		{"Neil", "Nell"},
+		{"Nelly", "Nell"},
+		{"Webmasters", "Webmaster"},
+		{"The Webmaster", "Webmaster"},
+		{"RetroBob", "Retro Bot"},
+		{"Discord Dan", "Discord Dan"},
+		{"Disc0rd_Dan", "Discord Dan"},
+		// Allowed
+		{"John", ""},
+		{"Sparky", ""},
+		{"Nebula", ""},
+		// Too short to protect
+		{"Al", ""},
+		{"A1", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nickname, func(t *testing.T) {
+			protected, found := FindImpersonated(tt.nickname)
+			if tt.want == "" && found {
+				t.Errorf("FindImpersonated(%q) = %q, want it allowed", tt.nickname, protected.Nickname)
+			}
+			if tt.want != "" && protected.Nickname != tt.want {
+				t.Errorf("FindImpersonated(%q) = %q, want %q", tt.nickname, protected.Nickname, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package chat
+
+import "time"
+
+type KickNotice struct {
+	Message string
+	Time    time.Time
+}
+
+var (
+	// Why users were kicked out, web users only find out on their next update
+	kickNotices map[string]KickNotice = make(map[string]KickNotice)
+)
+
+// KickUser takes the user out of the room and remembers why.
+func KickUser(combinedId string, message string) {
+	user, found := GetUser(combinedId)
+	if !found {
+		return
+	}
+
+	mutex.Lock()
+	now := time.Now().UTC()
+	for id, notice := range kickNotices {
+		if now.Sub(notice.Time) > KICK_NOTICE_TIMEOUT_MIN*time.Minute {
+			delete(kickNotices, id)
+		}
+	}
+	kickNotices[combinedId] = KickNotice{Message: message, Time: now}
+	mutex.Unlock()
+
+	RoomEvents[user.RoomId].Publish(ChatUserKickedEvent{
+		UserID:  combinedId,
+		Message: message,
+	})
+
+	DeregisterUser(combinedId)
+}
+
+// GetKickNotice tells why the user was kicked out, if they were.
+func GetKickNotice(combinedId string) (KickNotice, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	notice, found := kickNotices[combinedId]
+	if !found || time.Since(notice.Time) > KICK_NOTICE_TIMEOUT_MIN*time.Minute {
+		return KickNotice{}, false
+	}
+
+	return notice, true
+}
@@ -0,0 +1,181 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/helpers"
+	"retro-chat-rooms/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const MEMOS_DOCUMENT = "memos"
+
+var (
+	// Memos waiting for their recipient to show up
+	memos []Memo = make([]Memo, 0)
+
+	memosMutex = sync.Mutex{}
+)
+
+var (
+	ErrMemoRecipientOnline = errors.New("they are online, send them a private message instead")
+	ErrMemoEmpty           = errors.New("the memo is empty")
+	ErrMemoTooLong         = fmt.Errorf("memos can have at most %d characters", MEMO_MAX_LENGTH)
+	ErrMemoSenderQuota     = errors.New("you have too many memos waiting to be delivered")
+	ErrMemoRecipientFull   = errors.New("they have too many memos waiting already")
+)
+
+func memoExpiryDays() int {
+	if config.Current.Memos.ExpiryDays > 0 {
+		return config.Current.Memos.ExpiryDays
+	}
+	return MEMO_DEFAULT_EXPIRY_DAYS
+}
+
+func memoMaxPerSender() int {
+	if config.Current.Memos.MaxPerSender > 0 {
+		return config.Current.Memos.MaxPerSender
+	}
+	return MEMO_DEFAULT_MAX_PER_SENDER
+}
+
+func memoMaxPerRecipient() int {
+	if config.Current.Memos.MaxPerRecipient > 0 {
+		return config.Current.Memos.MaxPerRecipient
+	}
+	return MEMO_DEFAULT_MAX_PER_RECIPIENT
+}
+
+func normalizeNickname(nickname string) string {
+	return strings.TrimSpace(strings.ToLower(nickname))
+}
+
+func loadMemos() {
+	defer memosMutex.Unlock()
+	memosMutex.Lock()
+
+	_, err := storage.Current.Load(MEMOS_DOCUMENT, &memos)
+	if err != nil {
+		log.Printf("Error loading memos: %v", err)
+	}
+}
+
+// saveMemos expects memosMutex to be locked.
+func saveMemos() {
+	err := storage.Current.Save(MEMOS_DOCUMENT, memos)
+	if err != nil {
+		log.Printf("Error saving memos: %v", err)
+	}
+}
+
+// pruneMemos drops expired memos, expects memosMutex to be locked.
+func pruneMemos(now time.Time) {
+	memos = lo.Filter(memos, func(m Memo, _ int) bool {
+		return now.Before(m.ExpiresAt)
+	})
+}
+
+// LeaveMemo stores a message for someone who is not online, it gets
+// delivered the next time someone joins with that nickname.
+func LeaveMemo(from ChatUser, fromIP string, toNickname string, message string) error {
+	message = strings.TrimSpace(message)
+	to := normalizeNickname(toNickname)
+
+	if message == "" || to == "" {
+		return ErrMemoEmpty
+	}
+
+	if len(message) > MEMO_MAX_LENGTH {
+		return ErrMemoTooLong
+	}
+
+	if _, online := GetUserByNickname(to); online {
+		return ErrMemoRecipientOnline
+	}
+
+	now := time.Now().UTC()
+	sender := normalizeNickname(from.Nickname)
+
+	defer memosMutex.Unlock()
+	memosMutex.Lock()
+
+	pruneMemos(now)
+
+	sent := lo.CountBy(memos, func(m Memo) bool {
+		return normalizeNickname(m.From) == sender || (fromIP != "" && m.FromIP == fromIP)
+	})
+
+	if sent >= memoMaxPerSender() {
+		return ErrMemoSenderQuota
+	}
+
+	received := lo.CountBy(memos, func(m Memo) bool {
+		return m.To == to
+	})
+
+	if received >= memoMaxPerRecipient() {
+		return ErrMemoRecipientFull
+	}
+
+	memos = append(memos, Memo{
+		ID:        uuid.NewString(),
+		From:      from.Nickname,
+		FromIP:    fromIP,
+		To:        to,
+		Message:   message,
+		Time:      now,
+		ExpiresAt: now.AddDate(0, 0, memoExpiryDays()),
+	})
+
+	saveMemos()
+
+	return nil
+}
+
+// TakeMemos removes and returns the memos for the nickname.
+func TakeMemos(nickname string) []Memo {
+	to := normalizeNickname(nickname)
+
+	defer memosMutex.Unlock()
+	memosMutex.Lock()
+
+	pruneMemos(time.Now().UTC())
+
+	pending, rest := lo.FilterReject(memos, func(m Memo, _ int) bool {
+		return m.To == to
+	})
+
+	if len(pending) > 0 {
+		memos = rest
+		saveMemos()
+	}
+
+	return pending
+}
+
+// SentAt is when the memo was left, for showing next to it.
+func (m Memo) SentAt() string {
+	return helpers.FormatTimestamp(m.Time) + " " + m.Time.Format("Jan 2")
+}
+
+// deliverMemos shows web and native users their memos, Discord users
+// get theirs as direct messages when they first speak in a channel.
+func deliverMemos(user ChatUser) {
+	for _, memo := range TakeMemos(user.Nickname) {
+		// The sender's nickname was escaped when they joined
+		SendSystemNotice(user, fmt.Sprintf(
+			"{nickname}, <strong>%s</strong> left you a memo at %s: %s",
+			memo.From,
+			memo.SentAt(),
+			template.HTMLEscapeString(memo.Message),
+		))
+	}
+}
@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"fmt"
+	"math"
+	"retro-chat-rooms/netblocks"
+	"time"
+)
+
+var (
+	// Key/Value list of when each muted user can talk again, by room
+	// and moderationKey
+	userMutes map[string]time.Time = make(map[string]time.Time)
+)
+
+// moderationKey is who the user is beyond their session, like bans do,
+// so leaving and joining again doesn't shake off a mute or spam strikes.
+func moderationKey(user ChatUser) string {
+	switch {
+	case user.IsDiscordUser():
+		return "discord:" + user.DiscordId
+	case user.IP != "":
+		return "ip:" + netblocks.GroupKey(user.IP)
+	}
+	return "user:" + user.ID
+}
+
+func muteKey(user ChatUser) string {
+	return user.RoomId + "|" + moderationKey(user)
+}
+
+func MuteUser(user ChatUser, until time.Time) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	userMutes[muteKey(user)] = until
+}
+
+func UnmuteUser(user ChatUser) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	delete(userMutes, muteKey(user))
+}
+
+// GetMuteEnd returns when the user can talk again, if they are muted.
+func GetMuteEnd(user ChatUser) (time.Time, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	key := muteKey(user)
+	until, found := userMutes[key]
+	if !found {
+		return time.Time{}, false
+	}
+
+	if time.Now().UTC().After(until) {
+		delete(userMutes, key)
+		return time.Time{}, false
+	}
+
+	return until, true
+}
+
+func IsUserMuted(user ChatUser) bool {
+	_, muted := GetMuteEnd(user)
+	return muted
+}
+
+// MuteNotice tells a muted user how long until they can talk again,
+// returns false if they aren't muted.
+func MuteNotice(user ChatUser) (string, bool) {
+	until, muted := GetMuteEnd(user)
+	if !muted {
+		return "", false
+	}
+
+	return "you have been muted by a moderator, you can talk again in " + FormatRemainingTime(time.Until(until)) + ".", true
+}
+
+func FormatRemainingTime(d time.Duration) string {
+	// Rounded up, "0 seconds" left would be confusing
+	if d < time.Minute {
+		seconds := int(math.Ceil(d.Seconds()))
+		if seconds == 1 {
+			return "1 second"
+		}
+		return fmt.Sprintf("%d seconds", seconds)
+	}
+
+	minutes := int(math.Ceil(d.Minutes()))
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMuteOutlivesSession(t *testing.T) {
+	muted := ChatUser{ID: "session-1", RoomId: "general", IP: "192.0.2.1"}
+	MuteUser(muted, time.Now().UTC().Add(time.Minute))
+	defer UnmuteUser(muted)
+
+	tests := []struct {
+		name string
+		user ChatUser
+		want bool
+	}{
+		{"same session", muted, true},
+		{"joined again", ChatUser{ID: "session-2", RoomId: "general", IP: "192.0.2.1"}, true},
+		{"other room", ChatUser{ID: "session-3", RoomId: "other", IP: "192.0.2.1"}, false},
+		{"other IP", ChatUser{ID: "session-4", RoomId: "general", IP: "192.0.2.2"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUserMuted(tt.user); got != tt.want {
+				t.Errorf("IsUserMuted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMuteIPv6Prefix(t *testing.T) {
+	muted := ChatUser{ID: "session-1", RoomId: "general", IP: "2001:db8:1:2::1"}
+	MuteUser(muted, time.Now().UTC().Add(time.Minute))
+	defer UnmuteUser(muted)
+
+	if !IsUserMuted(ChatUser{ID: "session-2", RoomId: "general", IP: "2001:db8:1:2::beef"}) {
+		t.Error("a new address in the same /64 isn't muted")
+	}
+	if IsUserMuted(ChatUser{ID: "session-3", RoomId: "general", IP: "2001:db8:1:3::1"}) {
+		t.Error("another /64 is muted")
+	}
+}
+
+func TestMuteDiscordUser(t *testing.T) {
+	muted := ChatUser{ID: "combined-1", RoomId: "general", DiscordId: "1234"}
+	MuteUser(muted, time.Now().UTC().Add(time.Minute))
+
+	if !IsUserMuted(ChatUser{ID: "combined-2", RoomId: "general", DiscordId: "1234"}) {
+		t.Error("the Discord user isn't muted")
+	}
+
+	UnmuteUser(ChatUser{ID: "combined-2", RoomId: "general", DiscordId: "1234"})
+	if IsUserMuted(muted) {
+		t.Error("the Discord user is still muted after unmuting")
+	}
+}
+
+func TestMuteExpires(t *testing.T) {
+	muted := ChatUser{ID: "session-1", RoomId: "general", IP: "192.0.2.9"}
+	MuteUser(muted, time.Now().UTC().Add(-time.Second))
+
+	if IsUserMuted(muted) {
+		t.Error("an expired mute still counts")
+	}
+}
@@ -0,0 +1,53 @@
+package chat
+
+import (
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// PurgeUserMessages removes everything the user said in their room
+// and returns the IDs of the removed messages.
+func PurgeUserMessages(combinedId string) []string {
+	mutex.Lock()
+
+	user, found := users[combinedId]
+	if !found {
+		mutex.Unlock()
+		return make([]string, 0)
+	}
+
+	purged := make(map[string]bool)
+	var since time.Time
+
+	keep := func(messages []*ChatMessage) []*ChatMessage {
+		return lo.Filter(messages, func(m *ChatMessage, _ int) bool {
+			if m.IsSystemMessage || m.From != combinedId {
+				return true
+			}
+			purged[m.ID] = true
+			if since.IsZero() || m.Time.Before(since) {
+				since = m.Time
+			}
+			return false
+		})
+	}
+
+	for _, uid := range roomUsers[user.RoomId] {
+		userMessages[uid] = keep(userMessages[uid])
+	}
+
+	roomMessageHistory[user.RoomId] = keep(roomMessageHistory[user.RoomId])
+
+	ids := lo.Keys(purged)
+	mutex.Unlock()
+
+	RoomEvents[user.RoomId].Publish(ChatMessagesPurgedEvent{
+		RoomID:     user.RoomId,
+		UserID:     combinedId,
+		MessageIDs: ids,
+		Since:      since,
+	})
+
+	return ids
+}
@@ -0,0 +1,71 @@
+package chat
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+func findMessage(messages []*ChatMessage, messageId string) (*ChatMessage, bool) {
+	return lo.Find(messages, func(m *ChatMessage) bool {
+		return m != nil && m.ID == messageId
+	})
+}
+
+// FindUserMessage looks for a message the user received, private ones included.
+func FindUserMessage(combinedId string, messageId string) (*ChatMessage, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	return findMessage(userMessages[combinedId], messageId)
+}
+
+// FindRoomMessage looks for a public message that was sent to the room.
+func FindRoomMessage(roomId string, messageId string) (*ChatMessage, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	if m, found := findMessage(roomMessageHistory[roomId], messageId); found {
+		return m, true
+	}
+
+	for _, combinedId := range roomUsers[roomId] {
+		m, found := findMessage(userMessages[combinedId], messageId)
+		if found && (!m.Privately || m.To == "") {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetRoomMessageHistory returns the latest public messages of the room.
+func GetRoomMessageHistory(roomId string) []*ChatMessage {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	return append([]*ChatMessage{}, roomMessageHistory[roomId]...)
+}
+
+// QuoteMessage creates the excerpt shown above a reply.
+func QuoteMessage(message *ChatMessage) *QuotedMessage {
+	if message == nil || message.IsSystemMessage {
+		return nil
+	}
+
+	nickname := ""
+	if from := message.GetFrom(); from != nil {
+		nickname = from.Nickname
+	}
+
+	excerpt := []rune(strings.Join(strings.Fields(message.Message), " "))
+	if len(excerpt) > QUOTE_EXCERPT_LENGTH {
+		excerpt = append(excerpt[:QUOTE_EXCERPT_LENGTH-3], []rune("...")...)
+	}
+
+	return &QuotedMessage{
+		ID:       message.ID,
+		Nickname: nickname,
+		Excerpt:  string(excerpt),
+	}
+}
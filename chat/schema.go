@@ -30,9 +30,19 @@ type ChatRoom struct {
 	IntroMessage       string
 	LastUserListUpdate time.Time
 	TextColor          string
+	// One message per this many seconds per user, 0 is off
+	SlowModeSec int
+}
+
+// QuotedMessage is the short version of a message someone replied to.
+type QuotedMessage struct {
+	ID       string
+	Nickname string
+	Excerpt  string
 }
 
 type ChatMessage struct {
+	ID                   string
 	RoomID               string
 	Time                 time.Time
 	Message              string
@@ -45,6 +55,9 @@ type ChatMessage struct {
 	Source               string
 	InvolvedUsers        []ChatUser
 	ShowClientIcon       bool
+	ReplyTo              *QuotedMessage
+	// Sent by a shadow banned user, only they and moderators get it
+	Shadowed bool
 }
 
 func (m *ChatMessage) GetFrom() *ChatUser {
@@ -87,12 +100,56 @@ type ChatUser struct {
 	IsAdmin   bool
 	RoomId    string
 	Client    ClientInfo
+	// Empty for Discord users
+	IP string
+	// One of ROLE_*, empty for regular users
+	Role string
+	// Identifies the web session, see bans.Fingerprint. Empty for native clients
+	Fingerprint string
+	// Shadow banned users only see their own messages
+	Shadowed bool
 }
 
 func (user ChatUser) IsDiscordUser() bool {
 	return user.DiscordId != ""
 }
 
+// IsStaff tells if the user moderates, voiced users don't.
+func (user ChatUser) IsStaff() bool {
+	return user.IsAdmin || (user.Role != "" && user.Role != ROLE_VOICED)
+}
+
+// IsTrusted tells if the user can post links in rooms that only allow
+// them for voiced users and staff.
+func (user ChatUser) IsTrusted() bool {
+	return user.IsStaff() || user.Role == ROLE_VOICED
+}
+
+type DirectMessage struct {
+	ID      string
+	Time    time.Time
+	From    ChatUser
+	To      ChatUser
+	Message string
+	// Sent by a shadow banned user, the recipient never gets it
+	Shadowed bool
+}
+
+type DirectMessageEvent struct {
+	Message *DirectMessage
+}
+
+type Memo struct {
+	ID     string
+	From   string
+	FromIP string
+	// Lowercase nickname of who it is for
+	To        string
+	Message   string
+	Time      time.Time
+	ExpiresAt time.Time
+}
+
 type ChatMessageEvent struct {
 	Message *ChatMessage
 }
@@ -105,11 +162,40 @@ type ChatUserLeftEvent struct {
 	User ChatUser
 }
 
+type ChatUserUpdatedEvent struct {
+	User ChatUser
+}
+
+// ChatRoomUpdatedEvent is published when a room's settings change
+type ChatRoomUpdatedEvent struct {
+	Room ChatRoom
+}
+
 type ChatUserKickedEvent struct {
 	UserID  string
 	Message string
 }
 
+// ChatMessagesPurgedEvent is published when a user's messages are removed
+type ChatMessagesPurgedEvent struct {
+	RoomID     string
+	UserID     string
+	MessageIDs []string
+	// When the oldest of them was sent
+	Since time.Time
+}
+
+// ChatModerationEvent is published whenever a user is acted upon.
+type ChatModerationEvent struct {
+	Action string
+	RoomID string
+	// Nickname of who did it, empty when it was automatic
+	Actor  string
+	Target ChatUser
+	Reason string
+	Time   time.Time
+}
+
 type ClientInfo struct {
 	// Platform (Web, Discord, Desktop)
 	Plat string
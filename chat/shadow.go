@@ -0,0 +1,46 @@
+package chat
+
+import "retro-chat-rooms/bans"
+
+// isShadowBanned checks the shadow bans for someone joining,
+// staff can't be shadowed.
+func isShadowBanned(user ChatUser) bool {
+	if user.IsStaff() {
+		return false
+	}
+
+	_, shadowed := bans.CheckShadow(bans.Candidate{
+		IP:          user.IP,
+		Nickname:    user.Nickname,
+		Fingerprint: user.Fingerprint,
+		DiscordId:   user.DiscordId,
+	})
+
+	return shadowed
+}
+
+func canSeeShadowed(user ChatUser) bool {
+	return user.IsStaff()
+}
+
+// CanSeeShadowed tells if the user gets messages from shadow banned users.
+func CanSeeShadowed(combinedId string) bool {
+	defer mutex.Unlock()
+	mutex.Lock()
+	return canSeeShadowed(users[combinedId])
+}
+
+// SetUserShadowed shadow bans someone who is online, or lifts it.
+// Nobody else is told about it.
+func SetUserShadowed(combinedId string, shadowed bool) (ChatUser, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	user, found := users[combinedId]
+	if found {
+		user.Shadowed = shadowed
+		users[combinedId] = user
+	}
+
+	return user, found
+}
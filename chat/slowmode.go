@@ -0,0 +1,67 @@
+package chat
+
+import (
+	"time"
+)
+
+var (
+	// Key/Value list of when each user last talked in their room
+	userLastRoomMessage map[string]time.Time = make(map[string]time.Time)
+
+	roomLastSettingsChange map[string]time.Time = make(map[string]time.Time)
+	userLastSettingsChange map[string]time.Time = make(map[string]time.Time)
+)
+
+// SetSlowMode changes how often users can talk in the room, 0 turns it off.
+func SetSlowMode(roomId string, seconds int) (ChatRoom, bool) {
+	mutex.Lock()
+	room, found := rooms[roomId]
+	if !found {
+		mutex.Unlock()
+		return ChatRoom{}, false
+	}
+
+	room.SlowModeSec = seconds
+	rooms[roomId] = room
+	roomLastSettingsChange[roomId] = time.Now().UTC()
+	mutex.Unlock()
+
+	RoomEvents[roomId].Publish(ChatRoomUpdatedEvent{Room: room})
+
+	return room, true
+}
+
+// GetSlowModeWait returns how long the user has to wait before
+// talking again in their room.
+func GetSlowModeWait(user ChatUser) (time.Duration, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	room := rooms[user.RoomId]
+	if room.SlowModeSec == 0 || user.IsStaff() {
+		return 0, false
+	}
+
+	wait := time.Until(userLastRoomMessage[user.ID].Add(time.Duration(room.SlowModeSec) * time.Second))
+
+	return wait, wait > 0
+}
+
+func recordRoomMessage(combinedId string) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	userLastRoomMessage[combinedId] = time.Now().UTC()
+}
+
+// HasRoomSettingsChanged works like HasUserListChanged for room settings.
+func HasRoomSettingsChanged(combinedId string) bool {
+	defer mutex.Unlock()
+	mutex.Lock()
+	user := users[combinedId]
+	lastUserChange := userLastSettingsChange[combinedId]
+	lastRoomChange := roomLastSettingsChange[user.RoomId]
+
+	userLastSettingsChange[combinedId] = time.Now().UTC()
+
+	return lastRoomChange.After(lastUserChange)
+}
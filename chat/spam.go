@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"html"
+	"retro-chat-rooms/spam"
+	"strings"
+	"time"
+)
+
+// countNamedUsers tells how many people in the room the text names.
+func countNamedUsers(roomId string, text string) int {
+	lowered := strings.ToLower(text)
+	count := 0
+
+	for _, user := range GetRoomUsers(roomId) {
+		nickname := strings.ToLower(html.UnescapeString(user.Nickname))
+		// Short nicknames show up inside ordinary words
+		if len(nickname) >= 3 && strings.Contains(lowered, nickname) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// GetSpamScore returns the spam strikes the user has right now.
+func GetSpamScore(user ChatUser) int {
+	return spam.Score(moderationKey(user))
+}
+
+// checkSpam runs the message through the spam filter, returns the notice
+// for the sender and false if the message shouldn't be sent.
+func checkSpam(user *ChatUser, text string) (string, bool) {
+	if !spam.IsEnabled() || user.IsStaff() {
+		return "", true
+	}
+
+	verdict := spam.Check(moderationKey(*user), text, countNamedUsers(user.RoomId, text))
+	reasons := strings.Join(verdict.Reasons, ", ")
+
+	switch verdict.Action {
+	case spam.ACTION_WARN:
+		return "Easy there {nickname}, that looks like spam (" + reasons + ").", true
+	case spam.ACTION_DROP:
+		return "Sorry {nickname}, your message looks like spam (" + reasons + ") and wasn't sent.", false
+	case spam.ACTION_MUTE:
+		duration := time.Duration(spam.MuteMinutes()) * time.Minute
+		MuteUser(*user, time.Now().UTC().Add(duration))
+
+		PublishModerationEvent(ChatModerationEvent{
+			Action: MODERATION_MUTE,
+			RoomID: user.RoomId,
+			Target: *user,
+			Reason: "Spam: " + reasons,
+		})
+
+		return "{nickname}, you have been muted for " + FormatRemainingTime(duration) + " for spamming.", false
+	}
+
+	return "", true
+}
@@ -3,7 +3,10 @@ package chat
 import (
 	"fmt"
 	"regexp"
+	"retro-chat-rooms/policies"
 	"strings"
+
+	"github.com/samber/lo"
 )
 
 func ExtractClientInfo(client string) ClientInfo {
@@ -72,3 +75,12 @@ func ClientInfoToMsgSource(info ClientInfo) string {
 
 	return ""
 }
+
+// GetSpeechModes lists the speech modes the room's policy allows.
+func GetSpeechModes(roomId string) []SpeechMode {
+	allowScream := policies.ForRoom(roomId).Screaming
+
+	return lo.Filter(SPEECH_MODES, func(mode SpeechMode, _ int) bool {
+		return allowScream || mode.Value != MODE_SCREAM_AT
+	})
+}
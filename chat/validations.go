@@ -1,12 +1,16 @@
 package chat
 
 import (
+	"errors"
 	"fmt"
+	"html"
 	"math"
 	"regexp"
+	"retro-chat-rooms/bans"
 	"retro-chat-rooms/config"
 	"retro-chat-rooms/floodcontrol"
-	"retro-chat-rooms/profanity"
+	"retro-chat-rooms/netblocks"
+	"retro-chat-rooms/policies"
 	"strconv"
 	"strings"
 	"time"
@@ -99,9 +103,39 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 		return ChatMessage{}, false
 	}
 
+	if notice, muted := MuteNotice(*user); muted {
+		return ChatMessage{
+			RoomID:               room.ID,
+			Time:                 now,
+			To:                   user.ID,
+			IsSystemMessage:      true,
+			Message:              "Sorry {nickname}, " + notice,
+			Privately:            true,
+			SystemMessageSubject: user,
+			SpeechMode:           MODE_SAY_TO,
+			InvolvedUsers:        []ChatUser{*user},
+			ShowClientIcon:       false,
+		}, true
+	}
+
+	if wait, slowed := GetSlowModeWait(*user); slowed {
+		return ChatMessage{
+			RoomID:               room.ID,
+			Time:                 now,
+			To:                   user.ID,
+			IsSystemMessage:      true,
+			Message:              "Slow mode is on {nickname}, you can talk again in " + FormatRemainingTime(wait) + ".",
+			Privately:            true,
+			SystemMessageSubject: user,
+			SpeechMode:           MODE_SAY_TO,
+			InvolvedUsers:        []ChatUser{*user},
+			ShowClientIcon:       false,
+		}, true
+	}
+
 	userIp := userState.GetUserIP()
 
-	floodcontrol.RecordMessage(userIp)
+	floodcontrol.RecordMessage(userIp, room.ID)
 
 	if floodcontrol.IsIPBanned(userIp) {
 		return ChatMessage{
@@ -115,7 +149,7 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 		}, true
 	}
 
-	if floodcontrol.IsCooldownPeriod(userIp) {
+	if floodcontrol.IsCooldownPeriod(userIp, room.ID) {
 		coolDownMessageSent := userState.GetCoolDownMessageSent()
 
 		if coolDownMessageSent {
@@ -130,8 +164,8 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 			To:              user.ID,
 			IsSystemMessage: true,
 			Message: fmt.Sprintf(
-				"Hey {nickname}, chill out, you'll be able to send messages again %d minutes after your last message attempt.",
-				int(floodcontrol.MESSAGE_FLOOD_COOLDOWN_SEC/60),
+				"Hey {nickname}, chill out, you'll be able to send messages again %s after your last message attempt.",
+				FormatRemainingTime(time.Duration(floodcontrol.SettingsFor(room.ID).CooldownSec)*time.Second),
 			),
 			Privately:            true,
 			SystemMessageSubject: user,
@@ -146,9 +180,11 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 	// Reset the "cooldown message sent" so if they flood again, we can show it
 	userState.SetCoolDownMessageSent(false)
 
+	policy := policies.ForRoom(room.ID)
+
 	// Check if there's slurs
 
-	if profanity.HasBlockedWords(inputMsg.Message) {
+	if policy.IsBlocked(inputMsg.Message) {
 		return ChatMessage{
 			RoomID:               room.ID,
 			Time:                 now,
@@ -163,6 +199,57 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 		}, true
 	}
 
+	if !policy.AllowsLinks(inputMsg.Message, user.IsTrusted()) {
+		return ChatMessage{
+			RoomID:               room.ID,
+			Time:                 now,
+			To:                   user.ID,
+			IsSystemMessage:      true,
+			Message:              "Sorry {nickname}, links aren't allowed in this room.",
+			Privately:            true,
+			SystemMessageSubject: user,
+			SpeechMode:           MODE_SAY_TO,
+			InvolvedUsers:        []ChatUser{*user},
+			ShowClientIcon:       false,
+		}, true
+	}
+
+	notice, allowed := checkSpam(user, inputMsg.Message)
+
+	if !allowed {
+		return ChatMessage{
+			RoomID:               room.ID,
+			Time:                 now,
+			To:                   user.ID,
+			IsSystemMessage:      true,
+			Message:              notice,
+			Privately:            true,
+			SystemMessageSubject: user,
+			SpeechMode:           MODE_SAY_TO,
+			InvolvedUsers:        []ChatUser{*user},
+			ShowClientIcon:       false,
+		}, true
+	}
+
+	if notice != "" {
+		SendSystemNotice(*user, notice)
+	}
+
+	if !policy.Screaming && inputMsg.SpeechMode == MODE_SCREAM_AT {
+		return ChatMessage{
+			RoomID:               room.ID,
+			Time:                 now,
+			To:                   user.ID,
+			IsSystemMessage:      true,
+			SystemMessageSubject: user,
+			Message:              "Sorry {nickname}, screaming isn't allowed in this room.",
+			Privately:            true,
+			SpeechMode:           MODE_SAY_TO,
+			InvolvedUsers:        []ChatUser{*user},
+			ShowClientIcon:       false,
+		}, true
+	}
+
 	// Check if user has screamed recently
 
 	lastScream := userState.GetLastScream()
@@ -187,6 +274,8 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 		userState.SetLastScream(now)
 	}
 
+	recordRoomMessage(user.ID)
+
 	involvedUsers := []ChatUser{*user}
 	toUser, foundToUser := GetUser(inputMsg.To)
 
@@ -194,7 +283,15 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 		involvedUsers = append(involvedUsers, toUser)
 	}
 
-	message := profanity.ReplaceSensoredProfanity(inputMsg.Message)
+	message := policy.Censor(inputMsg.Message)
+
+	// Users can only reply to something they have seen
+	var replyTo *QuotedMessage
+	if inputMsg.ReplyTo != nil {
+		if quoted, found := FindUserMessage(user.ID, inputMsg.ReplyTo.ID); found {
+			replyTo = QuoteMessage(quoted)
+		}
+	}
 
 	return ChatMessage{
 		RoomID:          room.ID,
@@ -206,14 +303,76 @@ func ValidateMessage(userState IUserState, inputMsg ChatMessage) (ChatMessage, b
 		Privately:       inputMsg.Privately,
 		SpeechMode:      inputMsg.SpeechMode,
 		InvolvedUsers:   involvedUsers,
+		ReplyTo:         replyTo,
 	}, true
 }
 
+// ValidateDirectMessage runs a direct message through the same checks
+// as room messages, the error is meant to be shown to the sender.
+func ValidateDirectMessage(userState IUserState, from ChatUser, to ChatUser, message string) (string, error) {
+	if len(strings.TrimSpace(message)) == 0 {
+		return "", errors.New("The message is empty.")
+	}
+
+	if to.ID == "" || to.ID == from.ID {
+		return "", errors.New("Pick someone else to talk to.")
+	}
+
+	if notice, muted := MuteNotice(from); muted {
+		return "", errors.New("Sorry, " + notice)
+	}
+
+	userIp := userState.GetUserIP()
+
+	floodcontrol.RecordMessage(userIp, from.RoomId)
+
+	if floodcontrol.IsIPBanned(userIp) || floodcontrol.IsCooldownPeriod(userIp, from.RoomId) {
+		return "", errors.New("Chill out, you're sending too many messages.")
+	}
+
+	// The sender's room decides what can be said
+	policy := policies.ForRoom(from.RoomId)
+
+	if policy.IsBlocked(message) {
+		return "", errors.New("Come on! Let's be nice! This is a place for having fun!")
+	}
+
+	if !policy.AllowsLinks(message, from.IsTrusted()) {
+		return "", errors.New("Sorry, links aren't allowed in this room.")
+	}
+
+	notice, allowed := checkSpam(&from, message)
+
+	if !allowed {
+		return "", errors.New(strings.ReplaceAll(notice, "{nickname}", html.UnescapeString(from.Nickname)))
+	}
+
+	if notice != "" {
+		SendSystemNotice(from, notice)
+	}
+
+	return policy.Censor(message), nil
+}
+
 func ValidateUser(userState IUserState, user ChatUser, errors *[]string) {
 	if floodcontrol.IsIPBanned(userState.GetUserIP()) {
 		*errors = append(*errors, "You have been temporarily kicked out for flooding, try again later.")
 	}
 
+	if blocklist, blocked := netblocks.Check(userState.GetUserIP()); blocked {
+		*errors = append(*errors, blocklist.Describe())
+	}
+
+	ban, banned := bans.Check(bans.Candidate{
+		IP:          userState.GetUserIP(),
+		Nickname:    user.Nickname,
+		Fingerprint: user.Fingerprint,
+	})
+
+	if banned {
+		*errors = append(*errors, ban.Describe())
+	}
+
 	if user.Nickname == "" {
 		*errors = append(*errors, "You must provide a Nickname.")
 	}
@@ -232,15 +391,19 @@ func ValidateUser(userState IUserState, user ChatUser, errors *[]string) {
 		*errors = append(*errors, "Nickname must be no more than 20 characters long.")
 	}
 
-	if profanity.IsProfaneNickname(user.Nickname) {
+	if !policies.ForRoom(user.RoomId).AllowsNickname(user.Nickname) {
 		*errors = append(*errors, "This nickname is not allowed.")
 	}
 
-	isOwnerVariation := IsNickVariation(user.Nickname, config.Current.OwnerChatUser.Nickname)
 	_, hasUserNickname := GetUserByNickname(user.Nickname)
 
+	isOwnerVariation := IsNickVariation(user.Nickname, config.Current.OwnerChatUser.Nickname)
+
 	if isOwnerVariation || hasUserNickname {
 		*errors = append(*errors, "Someone is already using this Nickname, try a different one.")
+	} else if protected, found := FindImpersonated(user.Nickname); found {
+		// Staff log in with a password, nobody else gets to look like them
+		*errors = append(*errors, "This nickname looks too much like "+protected.Nickname+", "+protected.Who+". Try one that's more your own.")
 	}
 
 	_, hasUser := GetUser(user.ID)
@@ -0,0 +1,184 @@
+package chatlog
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Logger writes a room's activity to one file per day.
+type Logger struct {
+	directory     string
+	retentionDays int
+	compress      bool
+
+	day   string
+	file  *os.File
+	mutex sync.Mutex
+}
+
+func NewLogger(roomId string, cfg config.LogsConfig) *Logger {
+	directory := cfg.Directory
+	if directory == "" {
+		directory = DEFAULT_DIRECTORY
+	}
+
+	return &Logger{
+		directory:     filepath.Join(directory, roomId),
+		retentionDays: cfg.RetentionDays,
+		compress:      cfg.Compress,
+	}
+}
+
+// rotate makes sure the file for the given day is open,
+// expects the mutex to be locked.
+func (l *Logger) rotate(now time.Time) error {
+	day := now.Format(FILE_DATE_FORMAT)
+
+	if l.file != nil && l.day == day {
+		return nil
+	}
+
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+
+	if err := os.MkdirAll(l.directory, 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filepath.Join(l.directory, day+FILE_EXTENSION), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	l.file = file
+	l.day = day
+
+	go cleanUp(l.directory, day, l.retentionDays, l.compress)
+
+	return nil
+}
+
+func (l *Logger) write(t time.Time, line string) error {
+	defer l.mutex.Unlock()
+	l.mutex.Lock()
+
+	t = t.UTC()
+
+	if err := l.rotate(t); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(l.file, "[%s] %s\n", t.Format("15:04:05"), oneLine(line))
+	return err
+}
+
+func (l *Logger) Close() error {
+	defer l.mutex.Unlock()
+	l.mutex.Lock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+var tagsExpr = regexp.MustCompile(`<[^>]*>`)
+
+// oneLine keeps anyone from faking log lines, control characters and
+// line breaks become spaces.
+func oneLine(line string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '\u2028' || r == '\u2029' {
+			return ' '
+		}
+		return r
+	}, line)
+}
+
+func plainText(input string) string {
+	return html.UnescapeString(tagsExpr.ReplaceAllString(input, ""))
+}
+
+func describeUser(user chat.ChatUser) string {
+	details := strings.TrimSpace(user.Client.Plat + " " + user.Client.OS)
+	if details == "" {
+		return plainText(user.Nickname)
+	}
+	return plainText(user.Nickname) + " (" + details + ")"
+}
+
+func (l *Logger) LogMessage(m *chat.ChatMessage) error {
+	// Private conversations and shadowed messages stay out of the logs
+	if (m.Privately && m.To != "") || m.Shadowed {
+		return nil
+	}
+
+	if m.IsSystemMessage {
+		// Joins and leaves are logged from their events, with more detail
+		if m.Message == chat.MESSAGE_USER_JOINED || m.Message == chat.MESSAGE_USER_LEFT {
+			return nil
+		}
+
+		subject := ""
+		if m.SystemMessageSubject != nil {
+			subject = m.SystemMessageSubject.Nickname
+		}
+
+		return l.write(m.Time, "-!- "+plainText(strings.ReplaceAll(m.Message, "{nickname}", subject)))
+	}
+
+	from := "?"
+	if user := m.GetFrom(); user != nil {
+		from = plainText(user.Nickname)
+	}
+
+	message := m.Message
+	if to := m.GetTo(); to != nil {
+		message = plainText(to.Nickname) + ": " + message
+	}
+
+	switch m.SpeechMode {
+	case chat.MODE_SCREAM_AT:
+		return l.write(m.Time, "<"+from+"> (screams) "+message)
+	case chat.MODE_WHISPER_TO:
+		return l.write(m.Time, "<"+from+"> (whispers) "+message)
+	}
+
+	return l.write(m.Time, "<"+from+"> "+message)
+}
+
+func (l *Logger) LogJoin(user chat.ChatUser) error {
+	return l.write(time.Now(), "--> "+describeUser(user)+" joined")
+}
+
+func (l *Logger) LogLeave(user chat.ChatUser) error {
+	return l.write(time.Now(), "<-- "+plainText(user.Nickname)+" left")
+}
+
+func (l *Logger) LogModeration(evt chat.ChatModerationEvent) error {
+	actor := evt.Actor
+	if actor == "" {
+		actor = "system"
+	}
+
+	line := fmt.Sprintf("*** %s: %s by %s", evt.Action, describeUser(evt.Target), plainText(actor))
+	if evt.Reason != "" {
+		line += " (" + evt.Reason + ")"
+	}
+
+	return l.write(evt.Time, line)
+}
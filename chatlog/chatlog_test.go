@@ -0,0 +1,173 @@
+package chatlog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var day = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func read(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func names(t *testing.T, directory string) []string {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := []string{}
+	for _, entry := range entries {
+		result = append(result, entry.Name())
+	}
+	return result
+}
+
+func TestRotation(t *testing.T) {
+	directory := t.TempDir()
+	logger := NewLogger("general", config.LogsConfig{Directory: directory})
+	defer logger.Close()
+
+	ann := chat.ChatUser{ID: "general-ann", Nickname: "Ann"}
+	say := func(at time.Time, text string) {
+		err := logger.LogMessage(&chat.ChatMessage{
+			Time: at, From: ann.ID, Message: text, SpeechMode: chat.MODE_SAY_TO, InvolvedUsers: []chat.ChatUser{ann},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	say(day.Add(23*time.Hour), "late")
+	say(day.Add(25*time.Hour), "early")
+	// Local times still go in the UTC day
+	say(day.Add(26*time.Hour).In(time.FixedZone("UTC-5", -5*3600)), "later")
+
+	room := filepath.Join(directory, "general")
+	if got := names(t, room); !slices.Equal(got, []string{"2025-03-10.log", "2025-03-11.log"}) {
+		t.Fatalf("got files %v", got)
+	}
+
+	if got := read(t, filepath.Join(room, "2025-03-10.log")); got != "[23:00:00] <Ann> late\n" {
+		t.Errorf("first day: %q", got)
+	}
+	if got := read(t, filepath.Join(room, "2025-03-11.log")); got != "[01:00:00] <Ann> early\n[02:00:00] <Ann> later\n" {
+		t.Errorf("second day: %q", got)
+	}
+}
+
+func TestLogMessage(t *testing.T) {
+	ann := chat.ChatUser{ID: "general-ann", Nickname: "Ann &amp; co"}
+	bob := chat.ChatUser{ID: "general-bob", Nickname: "Bob"}
+	evil := chat.ChatUser{ID: "general-eve", Nickname: "Eve&#13;&#10;<-- Bob left"}
+	users := []chat.ChatUser{ann, bob, evil}
+
+	cases := []struct {
+		name    string
+		message chat.ChatMessage
+		want    string
+	}{
+		{"say", chat.ChatMessage{From: ann.ID, SpeechMode: chat.MODE_SAY_TO, Message: "hi"}, "<Ann & co> hi"},
+		{"scream at", chat.ChatMessage{From: ann.ID, To: bob.ID, SpeechMode: chat.MODE_SCREAM_AT, Message: "hey"}, "<Ann & co> (screams) Bob: hey"},
+		{"whisper", chat.ChatMessage{From: bob.ID, SpeechMode: chat.MODE_WHISPER_TO, Message: "psst"}, "<Bob> (whispers) psst"},
+		{"system", chat.ChatMessage{IsSystemMessage: true, SystemMessageSubject: &bob, Message: "<b>{nickname}</b> was kicked"}, "-!- Bob was kicked"},
+		{"private", chat.ChatMessage{From: ann.ID, To: bob.ID, Privately: true, Message: "secret"}, ""},
+		{"shadowed", chat.ChatMessage{From: ann.ID, Shadowed: true, Message: "spam"}, ""},
+		{"join message", chat.ChatMessage{IsSystemMessage: true, SystemMessageSubject: &bob, Message: chat.MESSAGE_USER_JOINED}, ""},
+		{"multi-line", chat.ChatMessage{From: bob.ID, SpeechMode: chat.MODE_SAY_TO, Message: "hi\r\n[00:00:01] <Ann & co> fake"}, "<Bob> hi  [00:00:01] <Ann & co> fake"},
+		{"escaped line break", chat.ChatMessage{IsSystemMessage: true, SystemMessageSubject: &evil, Message: "{nickname} was kicked"}, "-!- Eve  <-- Bob left was kicked"},
+		{"control characters", chat.ChatMessage{From: bob.ID, SpeechMode: chat.MODE_SAY_TO, Message: "a\tb\x1b[2Jc\u2028d"}, "<Bob> a b [2Jc d"},
+	}
+
+	for _, c := range cases {
+		directory := t.TempDir()
+		logger := NewLogger("general", config.LogsConfig{Directory: directory})
+
+		c.message.Time = day
+		c.message.InvolvedUsers = users
+		if err := logger.LogMessage(&c.message); err != nil {
+			t.Fatal(err)
+		}
+		logger.Close()
+
+		got := ""
+		if b, err := os.ReadFile(filepath.Join(directory, "general", "2025-03-10.log")); err == nil {
+			got = strings.TrimPrefix(strings.TrimSuffix(string(b), "\n"), "[00:00:00] ")
+		}
+		if got != c.want {
+			t.Errorf("%s: logged %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCleanUp(t *testing.T) {
+	cases := []struct {
+		name      string
+		retention int
+		compress  bool
+		want      []string
+	}{
+		{"keep everything", 0, false, []string{"2025-02-01.log", "2025-03-09.log", "2025-03-10.log", "notes.txt"}},
+		{"retention", 7, false, []string{"2025-03-09.log", "2025-03-10.log", "notes.txt"}},
+		{"compress", 0, true, []string{"2025-02-01.log.gz", "2025-03-09.log.gz", "2025-03-10.log", "notes.txt"}},
+		{"both", 7, true, []string{"2025-03-09.log.gz", "2025-03-10.log", "notes.txt"}},
+	}
+
+	for _, c := range cases {
+		directory := t.TempDir()
+		for _, name := range []string{"2025-02-01.log", "2025-03-09.log", "2025-03-10.log", "notes.txt"} {
+			if err := os.WriteFile(filepath.Join(directory, name), []byte(name+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		cleanUp(directory, "2025-03-10", c.retention, c.compress)
+
+		if got := names(t, directory); !slices.Equal(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestCompressFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2025-03-09.log")
+	if err := os.WriteFile(path, []byte("[10:00:00] <Ann> hi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compressFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path + GZIP_EXTENSION)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(reader)
+
+	if string(content) != "[10:00:00] <Ann> hi\n" {
+		t.Errorf("decompressed to %q", content)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("the uncompressed file is still there")
+	}
+}
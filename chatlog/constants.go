@@ -0,0 +1,9 @@
+package chatlog
+
+const (
+	DEFAULT_DIRECTORY = "logs"
+
+	FILE_DATE_FORMAT = "2006-01-02"
+	FILE_EXTENSION   = ".log"
+	GZIP_EXTENSION   = ".gz"
+)
@@ -0,0 +1,91 @@
+package chatlog
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileDate reads the day out of a log file name, ex: 2025-01-31.log.gz
+func fileDate(name string) (time.Time, bool) {
+	base := strings.TrimSuffix(strings.TrimSuffix(name, GZIP_EXTENSION), FILE_EXTENSION)
+
+	day, err := time.ParseInLocation(FILE_DATE_FORMAT, base, time.UTC)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return day, true
+}
+
+func compressFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.Create(path + GZIP_EXTENSION)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+
+	if _, err := io.Copy(writer, source); err != nil {
+		writer.Close()
+		target.Close()
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		target.Close()
+		return err
+	}
+
+	if err := target.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// cleanUp compresses the files from previous days and deletes
+// the ones past the retention period.
+func cleanUp(directory string, today string, retentionDays int, compress bool) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return
+	}
+
+	todayTime, _ := time.ParseInLocation(FILE_DATE_FORMAT, today, time.UTC)
+	oldest := todayTime.AddDate(0, 0, -retentionDays)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		day, ok := fileDate(name)
+
+		if entry.IsDir() || !ok || !day.Before(todayTime) {
+			continue
+		}
+
+		path := filepath.Join(directory, name)
+
+		if retentionDays > 0 && day.Before(oldest) {
+			if err := os.Remove(path); err != nil {
+				log.Printf("Error removing old chat log %s: %v", path, err)
+			}
+			continue
+		}
+
+		if compress && strings.HasSuffix(name, FILE_EXTENSION) {
+			if err := compressFile(path); err != nil {
+				log.Printf("Error compressing chat log %s: %v", path, err)
+			}
+		}
+	}
+}
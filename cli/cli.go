@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+type command struct {
+	Description string
+	Run         func(args []string) error
+}
+
+var commands = map[string]command{
+	"export": {
+		Description: "Export a room's stored history",
+		Run:         runExport,
+	},
+	"hash-password": {
+		Description: "Hash a moderator password read from stdin for config.yaml",
+		Run:         runHashPassword,
+	},
+	"migrate-passwords": {
+		Description: "Replace unsalted SHA-1 passwords in config.yaml with salted hashes",
+		Run:         runMigratePasswords,
+	},
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: retro-chat-rooms [command] [options]")
+	fmt.Fprintln(os.Stderr, "Runs the chat server when no command is given.")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+
+	names := lo.Keys(commands)
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, commands[name].Description)
+	}
+}
+
+// Run executes an admin command and returns the process exit code.
+func Run(args []string) int {
+	cmd, found := commands[args[0]]
+
+	if !found {
+		printUsage()
+		return 2
+	}
+
+	if err := cmd.Run(args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	return 0
+}
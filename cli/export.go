@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/export"
+	"retro-chat-rooms/history"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	roomId := flags.String("room", "", "id of the room to export")
+	format := flags.String("format", export.FORMAT_TEXT, "one of: "+strings.Join(export.FORMATS, ", "))
+	from := flags.String("from", "", "start date (UTC), YYYY-MM-DD or \"YYYY-MM-DD HH:MM\", defaults to 24 hours ago")
+	to := flags.String("to", "", "end date (UTC), defaults to now")
+	private := flags.Bool("private", false, "include private messages")
+	out := flags.String("out", "", "file to write to, defaults to stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	room, found := lo.Find(config.Current.Rooms, func(r config.ConfigChatRoom) bool {
+		return r.ID == *roomId
+	})
+
+	if !found {
+		return fmt.Errorf("room %q not found in config.yaml", *roomId)
+	}
+
+	if !export.IsValidFormat(*format) {
+		return errors.New("unknown format " + *format)
+	}
+
+	fromTime, toTime, err := export.ParseRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	records, err := history.Query(room.ID, fromTime, toTime, *private)
+	if err != nil {
+		return err
+	}
+
+	transcript := export.Transcript{
+		RoomID:   room.ID,
+		RoomName: room.Name,
+		Color:    room.Color,
+		From:     fromTime,
+		To:       toTime,
+		Records:  records,
+	}
+
+	if *out == "" {
+		return export.Write(os.Stdout, transcript, *format)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+
+	err = export.Write(file, transcript, *format)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	// Don't leave a truncated transcript behind
+	if err != nil {
+		os.Remove(*out)
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"retro-chat-rooms/passwords"
+	"strings"
+)
+
+// Matches "password: <sha1>" lines, keeping the indentation and quotes
+var legacyPasswordExpr = regexp.MustCompile(`(?m)^(\s*-?\s*password:\s*["']?)([0-9a-fA-F]{40})(["']?\s*)$`)
+
+func runHashPassword(args []string) error {
+	flags := flag.NewFlagSet("hash-password", flag.ContinueOnError)
+	useArgon2 := flags.Bool("argon2", false, "use argon2id instead of bcrypt")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return errors.New("no password given")
+	}
+
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return errors.New("no password given")
+	}
+
+	hash := ""
+	if *useArgon2 {
+		hash, err = passwords.HashArgon2(password)
+	} else {
+		hash, err = passwords.Hash(password)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(hash)
+	return nil
+}
+
+func runMigratePasswords(args []string) error {
+	flags := flag.NewFlagSet("migrate-passwords", flag.ContinueOnError)
+	file := flags.String("config", "config.yaml", "config file to update")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	migrated := 0
+	var wrapErr error
+
+	updated := legacyPasswordExpr.ReplaceAllStringFunc(string(content), func(line string) string {
+		parts := legacyPasswordExpr.FindStringSubmatch(line)
+
+		wrapped, err := passwords.WrapLegacy(parts[2])
+		if err != nil {
+			wrapErr = err
+			return line
+		}
+
+		migrated++
+		// Quoted, the hash starts with characters YAML doesn't like
+		return strings.TrimRight(parts[1], `"'`) + `"` + wrapped + `"` + strings.TrimLeft(parts[3], `"'`)
+	})
+
+	if wrapErr != nil {
+		return wrapErr
+	}
+
+	if migrated == 0 {
+		fmt.Fprintln(os.Stderr, "No SHA-1 passwords found in", *file)
+		return nil
+	}
+
+	if err := os.WriteFile(*file, []byte(updated), 0600); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Migrated %d passwords in %s, the same passwords still work.\n", migrated, *file)
+	return nil
+}
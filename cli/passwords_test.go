@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"retro-chat-rooms/passwords"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+const legacyConfig = `owner-chat-user:
+  nickname: Owner
+  password: e5e9fa1ba31ecd1ae84f75caaa474f3a663f05f4
+moderators:
+  - nickname: Mod
+    password: "E5E9FA1BA31ECD1AE84F75CAAA474F3A663F05F4"
+  - nickname: New
+    password: $2a$10$abcdefghijklmnopqrstuuM0Wh8B5vO2Rlz4pJ2WrG1xUZCSgS9pS
+`
+
+func TestMigratePasswords(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(legacyConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runMigratePasswords([]string{"-config", file}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var migrated struct {
+		Owner struct {
+			Password string `yaml:"password"`
+		} `yaml:"owner-chat-user"`
+		Moderators []struct {
+			Password string `yaml:"password"`
+		} `yaml:"moderators"`
+	}
+	if err := yaml.Unmarshal(content, &migrated); err != nil {
+		t.Fatalf("the migrated config isn't valid YAML: %v\n%s", err, content)
+	}
+
+	cases := []struct {
+		name string
+		hash string
+	}{
+		{"owner", migrated.Owner.Password},
+		{"quoted upper case", migrated.Moderators[0].Password},
+	}
+
+	for _, c := range cases {
+		if passwords.IsLegacy(c.hash) {
+			t.Errorf("%s: still a plain SHA-1", c.name)
+		}
+		if !passwords.Verify(c.hash, "secret") {
+			t.Errorf("%s: the old password no longer works", c.name)
+		}
+	}
+
+	if got := migrated.Moderators[1].Password; got != "$2a$10$abcdefghijklmnopqrstuuM0Wh8B5vO2Rlz4pJ2WrG1xUZCSgS9pS" {
+		t.Errorf("a bcrypt hash was changed to %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/floodcontrol"
+	"strings"
+)
+
+// Context holds who typed the command and how to answer them.
+type Context struct {
+	User chat.ChatUser
+	IP   string
+	// Reply sends a message only the user who typed the command sees
+	Reply func(message string)
+	// ReplyTo is the ID of the chat message the command was a reply to
+	ReplyTo string
+}
+
+type Command struct {
+	Usage string
+	Run   func(ctx Context, args string)
+}
+
+var registry = map[string]Command{}
+
+func register(name string, cmd Command) {
+	registry[name] = cmd
+}
+
+// NewContext creates a context that replies with private system messages
+// in the user's room, which works for web and native clients.
+func NewContext(user chat.ChatUser, ip string) Context {
+	return Context{
+		User: user,
+		IP:   ip,
+		Reply: func(message string) {
+			chat.SendSystemNotice(user, message)
+		},
+	}
+}
+
+// IsCommand tells if the message starts with a known command, anything
+// else starting with a slash is a normal message, ex: "/shrug" or "/usr".
+func IsCommand(message string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(message), " ")
+	return strings.HasPrefix(name, "/") && IsKnown(name[1:])
+}
+
+// IsKnown tells if there's a command with the name, without the slash.
+func IsKnown(name string) bool {
+	_, found := registry[strings.ToLower(name)]
+	return found
+}
+
+// Execute runs the command in the message, returns false if the
+// message isn't a known command.
+func Execute(ctx Context, message string) bool {
+	if !IsCommand(message) {
+		return false
+	}
+
+	name, args, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(message), "/"), " ")
+	cmd := registry[strings.ToLower(name)]
+
+	// Commands count toward flooding just like messages do
+	if ctx.IP != "" {
+		floodcontrol.RecordMessage(ctx.IP, ctx.User.RoomId)
+
+		if floodcontrol.IsCooldownPeriod(ctx.IP, ctx.User.RoomId) || floodcontrol.IsIPBanned(ctx.IP) {
+			return true
+		}
+	}
+
+	cmd.Run(ctx, strings.TrimSpace(args))
+	return true
+}
+
+func replyUsage(ctx Context, name string) {
+	ctx.Reply("Usage: " + registry[name].Usage)
+}
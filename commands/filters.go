@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"html/template"
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/profanity"
+	"retro-chat-rooms/roles"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+func init() {
+	register("filter", Command{
+		Usage: "/filter add|remove|list censored|blocked word, prefix the word with substring: or regex: to match more",
+		Run:   editFilter,
+	})
+}
+
+func editFilter(ctx Context, args string) {
+	if !roles.Has(ctx.User.Role, roles.PERM_WORD_FILTERS) {
+		ctx.Reply("You are not allowed to do that.")
+		return
+	}
+
+	fields := strings.SplitN(args, " ", 3)
+	if len(fields) < 2 || !lo.Contains(profanity.LISTS, fields[1]) {
+		replyUsage(ctx, "filter")
+		return
+	}
+
+	action, list := strings.ToLower(fields[0]), fields[1]
+
+	if action == "list" {
+		entries := lo.Map(profanity.List(list), func(e profanity.Entry, _ int) string {
+			return template.HTMLEscapeString(e.String())
+		})
+		ctx.Reply("The " + list + " words: " + strings.Join(entries, ", ") + ".")
+		return
+	}
+
+	if len(fields) < 3 || (action != "add" && action != "remove") {
+		replyUsage(ctx, "filter")
+		return
+	}
+
+	text := strings.TrimSpace(fields[2])
+	escaped := template.HTMLEscapeString(text)
+
+	if action == "remove" {
+		if err := profanity.Remove(list, text); err != nil {
+			ctx.Reply("Couldn't remove " + escaped + ": " + err.Error() + ".")
+			return
+		}
+
+		audit.Record(audit.Entry{
+			Action: audit.ACTION_FILTER_REMOVE,
+			Actor:  ctx.User.Nickname,
+			Target: list + " " + text,
+		})
+
+		ctx.Reply("Removed " + escaped + " from the " + list + " words.")
+		return
+	}
+
+	entry, err := profanity.ParseEntry(text)
+	if err == nil {
+		err = profanity.Add(list, entry)
+	}
+
+	if err != nil {
+		ctx.Reply("Couldn't add " + escaped + ": " + err.Error() + ".")
+		return
+	}
+
+	audit.Record(audit.Entry{
+		Action: audit.ACTION_FILTER_ADD,
+		Actor:  ctx.User.Nickname,
+		Target: list + " " + entry.String(),
+	})
+
+	ctx.Reply("Added " + escaped + " to the " + list + " words.")
+}
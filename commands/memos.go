@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"html/template"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/policies"
+	"strings"
+)
+
+func init() {
+	register("memo", Command{
+		Usage: "/memo nickname: message",
+		Run:   leaveMemo,
+	})
+}
+
+func leaveMemo(ctx Context, args string) {
+	nickname, message, found := strings.Cut(args, ":")
+
+	if !found || strings.TrimSpace(nickname) == "" || strings.TrimSpace(message) == "" {
+		replyUsage(ctx, "memo")
+		return
+	}
+
+	if notice, muted := chat.MuteNotice(ctx.User); muted {
+		ctx.Reply("Sorry, " + notice)
+		return
+	}
+
+	policy := policies.ForRoom(ctx.User.RoomId)
+
+	if policy.IsBlocked(message) {
+		ctx.Reply("Come on! Let's be nice! This is a place for having fun!")
+		return
+	}
+
+	err := chat.LeaveMemo(ctx.User, ctx.IP, nickname, policy.Censor(message))
+	if err != nil {
+		ctx.Reply("Couldn't leave the memo: " + err.Error() + ".")
+		return
+	}
+
+	ctx.Reply("Memo saved, " + template.HTMLEscapeString(strings.TrimSpace(nickname)) + " will get it the next time they join.")
+}
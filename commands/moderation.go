@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"html/template"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/moderation"
+	"retro-chat-rooms/roles"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	register("kick", Command{
+		Usage: "/kick nickname: reason",
+		Run:   kick,
+	})
+	register("ban", Command{
+		Usage: "/ban nickname: minutes reason",
+		Run:   ban,
+	})
+	register("mute", Command{
+		Usage: "/mute nickname: minutes reason",
+		Run:   mute,
+	})
+	register("unmute", Command{
+		Usage: "/unmute nickname",
+		Run:   unmute,
+	})
+	register("shadow", Command{
+		Usage: "/shadow nickname: minutes reason, without minutes it lasts until lifted",
+		Run:   shadow,
+	})
+	register("unshadow", Command{
+		Usage: "/unshadow nickname",
+		Run:   unshadow,
+	})
+	register("slow", Command{
+		Usage: "/slow seconds, or /slow off",
+		Run:   slow,
+	})
+}
+
+// findModerationTarget splits "nickname: rest" and checks the user
+// is allowed to use perm on them.
+func findModerationTarget(ctx Context, args string, perm string) (chat.ChatUser, string, bool) {
+	nickname, rest, _ := strings.Cut(args, ":")
+	nickname = strings.TrimSpace(nickname)
+
+	target, found := chat.GetUserByNickname(nickname)
+	if !found {
+		ctx.Reply(template.HTMLEscapeString(nickname) + " is not online.")
+		return chat.ChatUser{}, "", false
+	}
+
+	if !roles.CanModerate(roles.RoleFor(ctx.User, target.RoomId), target, perm) {
+		ctx.Reply("You are not allowed to do that.")
+		return chat.ChatUser{}, "", false
+	}
+
+	return target, strings.TrimSpace(rest), true
+}
+
+func kick(ctx Context, args string) {
+	if args == "" {
+		replyUsage(ctx, "kick")
+		return
+	}
+
+	target, reason, ok := findModerationTarget(ctx, args, roles.PERM_KICK)
+	if !ok {
+		return
+	}
+
+	moderation.Kick(ctx.User.Nickname, target, reason)
+}
+
+func ban(ctx Context, args string) {
+	if args == "" {
+		replyUsage(ctx, "ban")
+		return
+	}
+
+	target, rest, ok := findModerationTarget(ctx, args, roles.PERM_BAN)
+	if !ok {
+		return
+	}
+
+	minutesArg, reason, _ := strings.Cut(rest, " ")
+	minutes, err := strconv.Atoi(minutesArg)
+
+	if err != nil {
+		// No minutes given, it's all reason
+		minutes = moderation.DEFAULT_BAN_MIN
+		reason = rest
+	}
+
+	if minutes < 1 || minutes > moderation.MAX_BAN_MIN {
+		ctx.Reply("Bans last between 1 and " + strconv.Itoa(moderation.MAX_BAN_MIN) + " minutes.")
+		return
+	}
+
+	moderation.Ban(ctx.User.Nickname, target, strings.TrimSpace(reason), time.Duration(minutes)*time.Minute)
+}
+
+func mute(ctx Context, args string) {
+	if args == "" {
+		replyUsage(ctx, "mute")
+		return
+	}
+
+	target, rest, ok := findModerationTarget(ctx, args, roles.PERM_MUTE)
+	if !ok {
+		return
+	}
+
+	minutesArg, reason, _ := strings.Cut(rest, " ")
+	minutes, err := strconv.Atoi(minutesArg)
+
+	if err != nil {
+		minutes = moderation.DEFAULT_MUTE_MIN
+		reason = rest
+	}
+
+	if minutes < 1 || minutes > moderation.MAX_BAN_MIN {
+		ctx.Reply("Mutes last between 1 and " + strconv.Itoa(moderation.MAX_BAN_MIN) + " minutes.")
+		return
+	}
+
+	moderation.Mute(ctx.User.Nickname, target, strings.TrimSpace(reason), time.Duration(minutes)*time.Minute)
+	ctx.Reply(target.Nickname + " is muted for " + chat.FormatRemainingTime(time.Duration(minutes)*time.Minute) + ".")
+}
+
+func unmute(ctx Context, args string) {
+	if args == "" {
+		replyUsage(ctx, "unmute")
+		return
+	}
+
+	target, _, ok := findModerationTarget(ctx, args, roles.PERM_MUTE)
+	if !ok {
+		return
+	}
+
+	if !chat.IsUserMuted(target) {
+		ctx.Reply(target.Nickname + " is not muted.")
+		return
+	}
+
+	moderation.Unmute(ctx.User.Nickname, target)
+	ctx.Reply(target.Nickname + " can talk again.")
+}
+
+func shadow(ctx Context, args string) {
+	if args == "" {
+		replyUsage(ctx, "shadow")
+		return
+	}
+
+	target, rest, ok := findModerationTarget(ctx, args, roles.PERM_BAN)
+	if !ok {
+		return
+	}
+
+	minutesArg, reason, _ := strings.Cut(rest, " ")
+	minutes, err := strconv.Atoi(minutesArg)
+
+	if err != nil {
+		minutes = 0
+		reason = rest
+	}
+
+	if minutes < 0 {
+		replyUsage(ctx, "shadow")
+		return
+	}
+
+	moderation.Shadow(ctx.User.Nickname, target, strings.TrimSpace(reason), time.Duration(minutes)*time.Minute)
+	ctx.Reply(target.Nickname + " is shadow banned, only moderators will see what they say.")
+}
+
+func unshadow(ctx Context, args string) {
+	if args == "" {
+		replyUsage(ctx, "unshadow")
+		return
+	}
+
+	target, _, ok := findModerationTarget(ctx, args, roles.PERM_BAN)
+	if !ok {
+		return
+	}
+
+	if !target.Shadowed {
+		ctx.Reply(target.Nickname + " is not shadow banned.")
+		return
+	}
+
+	moderation.Unshadow(ctx.User.Nickname, target)
+	ctx.Reply(target.Nickname + " is not shadow banned anymore.")
+}
+
+func slow(ctx Context, args string) {
+	if !roles.Has(roles.RoleFor(ctx.User, ctx.User.RoomId), roles.PERM_ROOM_SETTINGS) {
+		ctx.Reply("You are not allowed to do that.")
+		return
+	}
+
+	seconds, err := strconv.Atoi(args)
+	if strings.EqualFold(args, "off") {
+		seconds, err = 0, nil
+	}
+
+	if err != nil || seconds < 0 || seconds > chat.MAX_SLOW_MODE_SEC {
+		ctx.Reply("Slow mode takes between 1 and " + strconv.Itoa(chat.MAX_SLOW_MODE_SEC) + " seconds, or off.")
+		return
+	}
+
+	moderation.SetSlowMode(ctx.User.Nickname, ctx.User.RoomId, seconds)
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/policies"
+	"retro-chat-rooms/polls"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+func init() {
+	register("poll", Command{
+		Usage: "/poll minutes question | option 1 | option 2 ...",
+		Run:   openPoll,
+	})
+	register("vote", Command{
+		Usage: "/vote option-number",
+		Run:   vote,
+	})
+	register("endpoll", Command{
+		Usage: "/endpoll",
+		Run:   endPoll,
+	})
+}
+
+func openPoll(ctx Context, args string) {
+	minutesArg, rest, _ := strings.Cut(args, " ")
+	minutes, err := strconv.Atoi(minutesArg)
+	parts := strings.Split(rest, "|")
+
+	if err != nil || len(parts) < 2 {
+		replyUsage(ctx, "poll")
+		return
+	}
+
+	if notice, muted := chat.MuteNotice(ctx.User); muted {
+		ctx.Reply("Sorry, " + notice)
+		return
+	}
+
+	// Polls go to the whole room, so they follow its rules like messages do
+	policy := policies.ForRoom(ctx.User.RoomId)
+
+	if lo.SomeBy(parts, policy.IsBlocked) {
+		ctx.Reply("Come on! Let's be nice! This is a place for having fun!")
+		return
+	}
+
+	if !lo.EveryBy(parts, func(part string) bool {
+		return policy.AllowsLinks(part, ctx.User.IsTrusted())
+	}) {
+		ctx.Reply("Sorry, links aren't allowed in this room.")
+		return
+	}
+
+	parts = lo.Map(parts, func(part string, _ int) string { return policy.Censor(part) })
+
+	_, err = polls.OpenPoll(ctx.User, parts[0], parts[1:], minutes)
+	if err != nil {
+		ctx.Reply("Couldn't start the poll: " + err.Error() + ".")
+	}
+}
+
+func vote(ctx Context, args string) {
+	option, err := strconv.Atoi(args)
+	if err != nil {
+		replyUsage(ctx, "vote")
+		return
+	}
+
+	err = polls.Vote(ctx.User.RoomId, ctx.User, ctx.IP, option)
+	if err != nil {
+		ctx.Reply("Couldn't vote: " + err.Error() + ".")
+		return
+	}
+
+	ctx.Reply("Thanks for voting!")
+}
+
+func endPoll(ctx Context, args string) {
+	err := polls.ClosePoll(ctx.User.RoomId, ctx.User)
+	if err != nil {
+		ctx.Reply("Couldn't close the poll: " + err.Error() + ".")
+	}
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"retro-chat-rooms/reports"
+	"strings"
+)
+
+func init() {
+	register("report", Command{
+		Usage: "reply to a message with /report reason, or /report message-id reason",
+		Run:   report,
+	})
+}
+
+func report(ctx Context, args string) {
+	messageId, reason := ctx.ReplyTo, args
+
+	if messageId == "" {
+		messageId, reason, _ = strings.Cut(args, " ")
+	}
+
+	if messageId == "" {
+		replyUsage(ctx, "report")
+		return
+	}
+
+	_, err := reports.Submit(ctx.User, ctx.IP, messageId, reason)
+	if err != nil {
+		ctx.Reply("Sorry, " + err.Error() + ".")
+		return
+	}
+
+	ctx.Reply("Thanks, a moderator will look at your report.")
+}
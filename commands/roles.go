@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"html/template"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/roles"
+	"strings"
+)
+
+func init() {
+	register("role", Command{
+		Usage: "/role nickname voiced|room-operator|global-moderator|none",
+		Run:   assignRole,
+	})
+}
+
+func assignRole(ctx Context, args string) {
+	space := strings.LastIndex(args, " ")
+	if space < 0 {
+		replyUsage(ctx, "role")
+		return
+	}
+
+	nickname := strings.TrimSpace(args[:space])
+	role := strings.ToLower(strings.TrimSpace(args[space+1:]))
+
+	if role == "none" {
+		role = ""
+	}
+
+	target, found := chat.GetUserByNickname(nickname)
+	if !found {
+		ctx.Reply(template.HTMLEscapeString(nickname) + " is not online.")
+		return
+	}
+
+	err := roles.Assign(ctx.User.Nickname, roles.RoleFor(ctx.User, target.RoomId), target, role)
+	if err != nil {
+		ctx.Reply("Couldn't change the role: " + err.Error() + ".")
+		return
+	}
+
+	if role == "" {
+		ctx.Reply(target.Nickname + " has no role anymore.")
+		return
+	}
+
+	ctx.Reply(target.Nickname + " is now " + roles.Badge(role) + ".")
+}
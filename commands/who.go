@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/roles"
+	"strings"
+)
+
+func init() {
+	register("who", Command{
+		Usage: "/who",
+		Run:   listUsers,
+	})
+}
+
+func listUsers(ctx Context, args string) {
+	online := chat.GetRoomOnlineUsers(ctx.User.RoomId)
+
+	if len(online) == 0 {
+		ctx.Reply("Nobody is in the room.")
+		return
+	}
+
+	names := []string{}
+	for _, user := range online {
+		name := user.Nickname
+		if badge := roles.Badge(user.Role); badge != "" {
+			name += " (" + badge + ")"
+		}
+		names = append(names, name)
+	}
+
+	ctx.Reply("In the room: " + strings.Join(names, ", ") + ".")
+}
@@ -7,6 +7,22 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// FloodControlConfig sets the message limits, 0 keeps the default.
+type FloodControlConfig struct {
+	// Messages in a row before the limit kicks in
+	Burst int `yaml:"burst"`
+	// Messages per second after that
+	PerSecond   float64 `yaml:"per-second"`
+	CooldownSec int     `yaml:"cooldown-sec"`
+	// Floods before a temporary ban
+	MaxFloods int `yaml:"max-floods"`
+	BanMin    int `yaml:"ban-min"`
+	// Minutes without a flood before one is forgiven
+	FloodDecayMin int `yaml:"flood-decay-min"`
+	// Only global, IPs quiet for this long are forgotten
+	IdleEvictionMin int `yaml:"idle-eviction-min"`
+}
+
 type ConfigChatRoom struct {
 	ID                   string `yaml:"id"`
 	Name                 string `yaml:"name"`
@@ -14,6 +30,17 @@ type ConfigChatRoom struct {
 	Color                string `yaml:"color"`
 	DiscordChannel       string `yaml:"discord-channel"`
 	ChatRoomIntroMessage string `yaml:"chat-room-intro-message"`
+	// One of the policies, the defaults are family friendly
+	Policy string `yaml:"policy"`
+	// arithmetic, image or questions
+	Captcha string `yaml:"captcha"`
+	// Overrides the global flood control
+	FloodControl FloodControlConfig `yaml:"flood-control"`
+}
+
+type CaptchaQuestionConfig struct {
+	Question string   `yaml:"question"`
+	Answers  []string `yaml:"answers"`
 }
 
 type OwnerChatUserConfig struct {
@@ -21,18 +48,139 @@ type OwnerChatUserConfig struct {
 	Id        string `yaml:"id"`
 	Nickname  string `yaml:"nickname"`
 	Color     string `yaml:"color"`
+	// Same formats as a moderator's password
+	Password string `yaml:"password"`
+}
+
+type ModeratorConfig struct {
+	Nickname string `yaml:"nickname"`
+	// A bcrypt or argon2id hash from "retro-chat-rooms hash-password" (add
+	// --argon2 for argon2id), or a "sha1:" one from migrate-passwords. Plain
+	// SHA-1 hex still logs in until migrate-passwords replaces it.
 	Password  string `yaml:"password"`
+	DiscordId string `yaml:"discord_id"`
+	Color     string `yaml:"color"`
+	// global-moderator, room-operator or voiced
+	Role string `yaml:"role"`
+	// Rooms where a room-operator or voiced user has their role, empty means all of them
+	Rooms []string `yaml:"rooms"`
 }
 
+type PollsConfig struct {
+	OpenToEveryone bool `yaml:"open-to-everyone"`
+	MaxOptions     int  `yaml:"max-options"`
+	MaxDurationMin int  `yaml:"max-duration-min"`
+}
+
+type StorageConfig struct {
+	// file or memory
+	Backend   string `yaml:"backend"`
+	Directory string `yaml:"directory"`
+}
+
+type MemosConfig struct {
+	ExpiryDays      int `yaml:"expiry-days"`
+	MaxPerSender    int `yaml:"max-per-sender"`
+	MaxPerRecipient int `yaml:"max-per-recipient"`
+}
+
+type LogsConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Directory string `yaml:"directory"`
+	// Days to keep old files for, 0 keeps them forever
+	RetentionDays int  `yaml:"retention-days"`
+	Compress      bool `yaml:"compress"`
+}
+
+// PolicyConfig is what people can say in the rooms using it.
+type PolicyConfig struct {
+	// allow, censor or block
+	CensoredWords string `yaml:"censored-words"`
+	BlockedWords  string `yaml:"blocked-words"`
+	NoScreaming   bool   `yaml:"no-screaming"`
+	// allow, block or trusted, which is voiced users and staff only
+	Links string `yaml:"links"`
+	// strict or relaxed, which only keeps blocked words out
+	Nicknames string `yaml:"nicknames"`
+}
+
+type IPGroupingConfig struct {
+	// IPv6 addresses in the same prefix count as one user, 64 unless set
+	IPv6Prefix int `yaml:"ipv6-prefix"`
+	// 32 unless set, which is every IPv4 address on its own
+	IPv4Prefix int `yaml:"ipv4-prefix"`
+}
+
+// IPBlocklistConfig is a file with one IP or CIDR range per line.
+type IPBlocklistConfig struct {
+	File   string `yaml:"file"`
+	Reason string `yaml:"reason"`
+}
+
+type ProfanityConfig struct {
+	// Where the word lists are, edits are picked up without a restart
+	Directory string `yaml:"directory"`
+}
+
+type SpamConfig struct {
+	Disabled bool `yaml:"disabled"`
+	// Messages and strikes older than this are forgotten
+	WindowSec       int `yaml:"window-sec"`
+	MaxRepeats      int `yaml:"max-repeats"`
+	MaxCapsPercent  int `yaml:"max-caps-percent"`
+	MaxCharacterRun int `yaml:"max-character-run"`
+	MaxMentions     int `yaml:"max-mentions"`
+	MaxLinks        int `yaml:"max-links"`
+	// Strikes within the window before warning, dropping and muting
+	WarnScore int `yaml:"warn-score"`
+	DropScore int `yaml:"drop-score"`
+	MuteScore int `yaml:"mute-score"`
+	MuteMin   int `yaml:"mute-min"`
+}
+
+// DiscordRoleConfig gives everyone with a Discord role a chat role,
+// only in the given rooms unless it's global.
+type DiscordRoleConfig struct {
+	ID    string   `yaml:"id"`
+	Role  string   `yaml:"role"`
+	Rooms []string `yaml:"rooms"`
+}
+
+// Shortest key the session cookies can be signed with
+const MIN_SESSION_SECRET_LENGTH = 32
+
 type Config struct {
-	SiteName             string              `yaml:"site-name"`
-	ChatRoomHeaderLogo   string              `yaml:"chat-room-header-logo"`
-	ChatRoomHeaderHeight string              `yaml:"chat-room-header-height"`
-	DiscordBotToken      string              `yaml:"discord-bot-token"`
-	DiscordWebhookId     string              `yaml:"discord-webhook-id"`
-	DiscordWebhookToken  string              `yaml:"discord-webhook-token"`
-	OwnerChatUser        OwnerChatUserConfig `yaml:"owner-chat-user"`
-	Rooms                []ConfigChatRoom    `yaml:"rooms"`
+	SiteName             string `yaml:"site-name"`
+	ChatRoomHeaderLogo   string `yaml:"chat-room-header-logo"`
+	ChatRoomHeaderHeight string `yaml:"chat-room-header-height"`
+	DiscordBotToken      string `yaml:"discord-bot-token"`
+	DiscordWebhookId     string `yaml:"discord-webhook-id"`
+	DiscordWebhookToken  string `yaml:"discord-webhook-token"`
+	// Where the moderation audit log is mirrored, optional
+	DiscordModerationChannel string              `yaml:"discord-moderation-channel"`
+	OwnerChatUser            OwnerChatUserConfig `yaml:"owner-chat-user"`
+	Moderators               []ModeratorConfig   `yaml:"moderators"`
+	// Nobody can join with these or anything that looks like them
+	ProtectedNicknames []string `yaml:"protected-nicknames"`
+	// Signs the session cookies, required
+	SessionSecret string `yaml:"session-secret"`
+	// How long moderators stay logged in to the admin area, 12 unless set
+	StaffSessionHours int                     `yaml:"staff-session-hours"`
+	DiscordRoles      []DiscordRoleConfig     `yaml:"discord-roles"`
+	Rooms             []ConfigChatRoom        `yaml:"rooms"`
+	Polls             PollsConfig             `yaml:"polls"`
+	Storage           StorageConfig           `yaml:"storage"`
+	Memos             MemosConfig             `yaml:"memos"`
+	Logs              LogsConfig              `yaml:"logs"`
+	Spam              SpamConfig              `yaml:"spam"`
+	Profanity         ProfanityConfig         `yaml:"profanity"`
+	Policies          map[string]PolicyConfig `yaml:"policies"`
+	// The captcha moderators solve to log in, image unless set
+	AdminCaptcha     string                  `yaml:"admin-captcha"`
+	CaptchaQuestions []CaptchaQuestionConfig `yaml:"captcha-questions"`
+	IPGrouping       IPGroupingConfig        `yaml:"ip-grouping"`
+	FloodControl     FloodControlConfig      `yaml:"flood-control"`
+	IPBlocklists     []IPBlocklistConfig     `yaml:"ip-blocklists"`
 }
 
 func LoadConfig() Config {
@@ -10,7 +10,17 @@ import (
 	"github.com/bwmarrin/discordgo" //discordgo package from the repo of bwmarrin .
 )
 
-func formatMessageForDiscord(m *chat.ChatMessage) discordgo.WebhookParams {
+// Webhooks can't send real replies, so replies show the quote
+// with a link to the original message instead.
+func formatQuoteForDiscord(quote *chat.QuotedMessage, jumpUrl string) string {
+	line := "> **" + quote.Nickname + "**: " + quote.Excerpt
+	if jumpUrl != "" {
+		line += " ([jump](" + jumpUrl + "))"
+	}
+	return line + "\n"
+}
+
+func formatMessageForDiscord(m *chat.ChatMessage, replyJumpUrl string) discordgo.WebhookParams {
 	from, _ := chat.GetUser(m.From)
 
 	if m.IsSystemMessage {
@@ -22,6 +32,10 @@ func formatMessageForDiscord(m *chat.ChatMessage) discordgo.WebhookParams {
 
 	message := ""
 
+	if m.ReplyTo != nil {
+		message += formatQuoteForDiscord(m.ReplyTo, replyJumpUrl)
+	}
+
 	// message += "*" + modeTransform[m.SpeechMode] + "* "
 
 	if m.To != "" {
@@ -73,7 +87,7 @@ func (bot *DiscordBot) Connect() {
 		return
 	}
 
-	dg.Identify.Intents = discordgo.IntentsGuildMessages
+	dg.Identify.Intents = discordgo.IntentsGuildMessages | discordgo.IntentsDirectMessages
 
 	err = dg.Open()
 
@@ -97,9 +111,14 @@ func (bot *DiscordBot) SendMessage(channel string, message *chat.ChatMessage) {
 		return
 	}
 
-	params := formatMessageForDiscord(message)
+	replyJumpUrl := ""
+	if message.ReplyTo != nil {
+		replyJumpUrl = bot.jumpUrl(message.ReplyTo.ID)
+	}
+
+	params := formatMessageForDiscord(message, replyJumpUrl)
 
-	_, err := bot.session.WebhookExecute(
+	sent, err := bot.session.WebhookExecute(
 		config.Current.DiscordWebhookId,
 		config.Current.DiscordWebhookToken,
 		true,
@@ -108,9 +127,104 @@ func (bot *DiscordBot) SendMessage(channel string, message *chat.ChatMessage) {
 
 	if err != nil {
 		fmt.Printf("There was an error sending discord message to %s: %s\n", channel, err.Error())
+		return
+	}
+
+	TrackMessage(message.ID, sent.ChannelID, sent.ID)
+}
+
+// jumpUrl links to the Discord copy of a chat message, if there is one.
+func (bot *DiscordBot) jumpUrl(chatId string) string {
+	bridged, found := findByChatId(chatId)
+	if !found {
+		return ""
+	}
+
+	channel, err := bot.session.State.Channel(bridged.ChannelID)
+	if err != nil {
+		return ""
+	}
+
+	return "https://discord.com/channels/" + channel.GuildID + "/" + bridged.ChannelID + "/" + bridged.DiscordID
+}
+
+// DeleteMessages removes the Discord copies of the given chat messages.
+// Bridged messages were posted by the webhook, messages written on
+// Discord need the bot to have the Manage Messages permission.
+func (bot *DiscordBot) DeleteMessages(chatIds []string) {
+	if bot.session == nil {
+		return
+	}
+
+	for _, chatId := range chatIds {
+		bridged, found := findByChatId(chatId)
+		if !found {
+			continue
+		}
+
+		err := bot.session.WebhookMessageDelete(
+			config.Current.DiscordWebhookId,
+			config.Current.DiscordWebhookToken,
+			bridged.DiscordID,
+		)
+
+		if err != nil {
+			err = bot.session.ChannelMessageDelete(bridged.ChannelID, bridged.DiscordID)
+		}
+
+		if err != nil {
+			fmt.Printf("There was an error deleting discord message %s: %s\n", bridged.DiscordID, err.Error())
+		}
 	}
 }
 
+// DeleteChannelMessage removes a message someone wrote on Discord,
+// it needs the Manage Messages permission.
+func (bot *DiscordBot) DeleteChannelMessage(channel string, messageId string) {
+	if bot.session == nil {
+		return
+	}
+
+	err := bot.session.ChannelMessageDelete(channel, messageId)
+
+	if err != nil {
+		fmt.Printf("There was an error deleting discord message %s: %s\n", messageId, err.Error())
+	}
+}
+
+// SendSystemMessage posts a plain message as the bot itself. It can
+// have text users wrote, like polls and reasons, so it never pings anyone.
+func (bot *DiscordBot) SendSystemMessage(channel string, content string) {
+	if bot.session == nil || channel == "" {
+		return
+	}
+
+	_, err := bot.session.ChannelMessageSendComplex(channel, &discordgo.MessageSend{
+		Content:         content,
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+
+	if err != nil {
+		fmt.Printf("There was an error sending discord message to %s: %s\n", channel, err.Error())
+	}
+}
+
+// SendDirectMessage sends a Discord DM to the given user.
+func (bot *DiscordBot) SendDirectMessage(discordId string, content string) {
+	if bot.session == nil || discordId == "" {
+		return
+	}
+
+	channel, err := bot.session.UserChannelCreate(discordId)
+
+	if err != nil {
+		fmt.Printf("There was an error opening a DM with %s: %s\n", discordId, err.Error())
+		return
+	}
+
+	bot.SendSystemMessage(channel.ID, content)
+}
+
 func (bot *DiscordBot) OnReceiveMessage(fn func(m *discordgo.MessageCreate)) {
 	if bot.session == nil {
 		return
@@ -0,0 +1,62 @@
+package discord
+
+import (
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+// How many bridged messages we remember, older
+// ones can't be replied to or deleted anymore.
+const MAX_BRIDGED_MESSAGES = 2000
+
+type bridgedMessage struct {
+	ChatID    string
+	ChannelID string
+	DiscordID string
+}
+
+var (
+	// Oldest first
+	bridgedMessages = make([]bridgedMessage, 0)
+
+	bridgedMutex = sync.Mutex{}
+)
+
+// TrackMessage remembers which Discord message a chat message became
+// (or came from) so replies can be mapped in both directions.
+func TrackMessage(chatId string, channelId string, discordId string) {
+	defer bridgedMutex.Unlock()
+	bridgedMutex.Lock()
+
+	if len(bridgedMessages) >= MAX_BRIDGED_MESSAGES {
+		bridgedMessages = bridgedMessages[len(bridgedMessages)-MAX_BRIDGED_MESSAGES+1:]
+	}
+
+	bridgedMessages = append(bridgedMessages, bridgedMessage{
+		ChatID:    chatId,
+		ChannelID: channelId,
+		DiscordID: discordId,
+	})
+}
+
+func findByChatId(chatId string) (bridgedMessage, bool) {
+	defer bridgedMutex.Unlock()
+	bridgedMutex.Lock()
+
+	return lo.Find(bridgedMessages, func(m bridgedMessage) bool {
+		return m.ChatID == chatId
+	})
+}
+
+// FindChatMessageId returns the chat message ID for a Discord message.
+func FindChatMessageId(discordId string) (string, bool) {
+	defer bridgedMutex.Unlock()
+	bridgedMutex.Lock()
+
+	m, found := lo.Find(bridgedMessages, func(m bridgedMessage) bool {
+		return m.DiscordID == discordId
+	})
+
+	return m.ChatID, found
+}
@@ -0,0 +1,32 @@
+package export
+
+import "retro-chat-rooms/chat"
+
+const (
+	FORMAT_TEXT  = "txt"
+	FORMAT_HTML  = "html"
+	FORMAT_IRC   = "irc"
+	FORMAT_JSONL = "jsonl"
+)
+
+var FORMATS = []string{FORMAT_TEXT, FORMAT_HTML, FORMAT_IRC, FORMAT_JSONL}
+
+var contentTypes = map[string]string{
+	FORMAT_TEXT:  "text/plain; charset=utf-8",
+	FORMAT_HTML:  "text/html; charset=utf-8",
+	FORMAT_IRC:   "text/plain; charset=utf-8",
+	FORMAT_JSONL: "application/x-ndjson",
+}
+
+var extensions = map[string]string{
+	FORMAT_TEXT:  "txt",
+	FORMAT_HTML:  "html",
+	FORMAT_IRC:   "log",
+	FORMAT_JSONL: "jsonl",
+}
+
+var speechModeLabels = map[string]string{
+	chat.MODE_SAY_TO:     "says to",
+	chat.MODE_SCREAM_AT:  "screams at",
+	chat.MODE_WHISPER_TO: "whispers to",
+}
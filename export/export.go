@@ -0,0 +1,203 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/history"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Transcript is a room's history for a time range.
+type Transcript struct {
+	RoomID   string
+	RoomName string
+	Color    string
+	From     time.Time
+	To       time.Time
+	Records  []history.Record
+}
+
+func IsValidFormat(format string) bool {
+	return lo.Contains(FORMATS, format)
+}
+
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+func FileName(t Transcript, format string) string {
+	return fmt.Sprintf("%s-%s-%s.%s", t.RoomID, t.From.Format("20060102"), t.To.Format("20060102"), extensions[format])
+}
+
+// Write renders the transcript in the given format.
+func Write(w io.Writer, t Transcript, format string) error {
+	switch format {
+	case FORMAT_TEXT:
+		return writeText(w, t)
+	case FORMAT_HTML:
+		return writeHTML(w, t)
+	case FORMAT_IRC:
+		return writeIRC(w, t)
+	case FORMAT_JSONL:
+		return writeJSONLines(w, t)
+	}
+
+	return ErrUnknownFormat
+}
+
+var tagsExpr = regexp.MustCompile(`<[^>]*>`)
+
+// plainSystemMessage turns the HTML of a system message into text.
+func plainSystemMessage(r history.Record) string {
+	message := strings.ReplaceAll(r.Message, "{nickname}", r.Subject)
+	return html.UnescapeString(tagsExpr.ReplaceAllString(message, ""))
+}
+
+func recipient(r history.Record) string {
+	if r.To == "" {
+		return "Everyone"
+	}
+	return r.To
+}
+
+func writeText(w io.Writer, t Transcript) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s chat transcript, %s to %s (UTC)\n\n",
+		t.RoomName, t.From.Format(time.DateTime), t.To.Format(time.DateTime))
+
+	for _, r := range t.Records {
+		fmt.Fprintf(&b, "[%s] ", r.Time.Format("2006-01-02 03:04:05 PM"))
+
+		if r.IsSystemMessage {
+			fmt.Fprintln(&b, plainSystemMessage(r))
+			continue
+		}
+
+		if r.ReplyTo != nil {
+			fmt.Fprintf(&b, "(replying to %s: \"%s\") ", r.ReplyTo.Nickname, r.ReplyTo.Excerpt)
+		}
+
+		private := ""
+		if r.IsPrivate() {
+			private = "privately "
+		}
+
+		fmt.Fprintf(&b, "%s %s%s %s: %s\n", r.From, private, speechModeLabels[r.SpeechMode], recipient(r), r.Message)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeIRC(w io.Writer, t Transcript) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- Log opened %s\n", t.From.Format("Mon Jan 02 15:04:05 2006"))
+
+	day := ""
+	for _, r := range t.Records {
+		if d := r.Time.Format("2006-01-02"); d != day {
+			if day != "" {
+				fmt.Fprintf(&b, "--- Day changed %s\n", r.Time.Format("Mon Jan 02 2006"))
+			}
+			day = d
+		}
+
+		fmt.Fprintf(&b, "%s ", r.Time.Format("15:04:05"))
+
+		if r.IsSystemMessage {
+			fmt.Fprintf(&b, "-!- %s\n", plainSystemMessage(r))
+			continue
+		}
+
+		message := r.Message
+		if r.SpeechMode == chat.MODE_SCREAM_AT {
+			message = strings.ToUpper(message)
+		}
+		if r.To != "" {
+			message = r.To + ": " + message
+		}
+
+		switch {
+		case r.IsPrivate():
+			fmt.Fprintf(&b, "-%s:%s- %s\n", r.From, r.To, r.Message)
+		case r.SpeechMode == chat.MODE_WHISPER_TO:
+			fmt.Fprintf(&b, " * %s whispers to %s: %s\n", r.From, recipient(r), r.Message)
+		default:
+			fmt.Fprintf(&b, "<%s> %s\n", r.From, message)
+		}
+	}
+
+	fmt.Fprintf(&b, "--- Log closed %s\n", t.To.Format("Mon Jan 02 15:04:05 2006"))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeJSONLines(w io.Writer, t Transcript) error {
+	encoder := json.NewEncoder(w)
+
+	for _, r := range t.Records {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var dateLayouts = []string{"2006-01-02 15:04", "2006-01-02T15:04", time.DateOnly}
+
+func parseDate(input string) (time.Time, bool, error) {
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, strings.TrimSpace(input), time.UTC)
+		if err == nil {
+			return t, layout == time.DateOnly, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("invalid date %q, use YYYY-MM-DD or YYYY-MM-DD HH:MM", input)
+}
+
+// ParseRange reads the from/to dates (UTC) of an export, the last
+// 24 hours are used when they are empty. A "to" date without a time
+// includes that whole day.
+func ParseRange(fromInput string, toInput string) (time.Time, time.Time, error) {
+	to := time.Now().UTC()
+	if toInput != "" {
+		t, dateOnly, err := parseDate(toInput)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if dateOnly {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		to = t
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if fromInput != "" {
+		t, _, err := parseDate(fromInput)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("the start date is after the end date")
+	}
+
+	return from, to, nil
+}
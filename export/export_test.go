@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/history"
+	"strings"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+
+var transcript = Transcript{
+	RoomID:   "general",
+	RoomName: "General",
+	Color:    "#C0C0C0",
+	From:     start,
+	To:       start.Add(48 * time.Hour),
+	Records: []history.Record{
+		{ID: "1", Time: start, IsSystemMessage: true, Subject: "Ann", Message: "{nickname} has joined the room!"},
+		{ID: "2", Time: start.Add(time.Minute), From: "Ann", SpeechMode: chat.MODE_SAY_TO, Message: "hi <b>all</b>"},
+		{ID: "3", Time: start.Add(2 * time.Minute), From: "Ann", To: "Bob", SpeechMode: chat.MODE_SCREAM_AT, Message: "hey"},
+		{ID: "4", Time: start.Add(25 * time.Hour), From: "Bob", To: "Ann", ToID: "general-ann", Privately: true, SpeechMode: chat.MODE_WHISPER_TO, Message: "psst"},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	cases := []struct {
+		format string
+		want   []string
+	}{
+		{FORMAT_TEXT, []string{
+			"General chat transcript, 2025-03-10 14:00:00 to 2025-03-12 14:00:00 (UTC)",
+			"[2025-03-10 02:00:00 PM] Ann has joined the room!",
+			"Ann says to Everyone: hi <b>all</b>",
+			"Ann screams at Bob: hey",
+			"Bob privately whispers to Ann: psst",
+		}},
+		{FORMAT_IRC, []string{
+			"--- Log opened Mon Mar 10 14:00:00 2025",
+			"14:00:00 -!- Ann has joined the room!",
+			"14:01:00 <Ann> hi <b>all</b>",
+			"14:02:00 <Ann> Bob: HEY",
+			"--- Day changed Tue Mar 11 2025",
+			"15:00:00 -Bob:Ann- psst",
+			"--- Log closed Wed Mar 12 14:00:00 2025",
+		}},
+		{FORMAT_HTML, []string{
+			"<title>General chat transcript</title>",
+			"hi &lt;b&gt;all&lt;/b&gt;",
+			`<font size="4"><strong>hey</strong></font>`,
+			"<i>privately</i>",
+			"<p><strong>Tuesday, March 11 2025</strong></p>",
+		}},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		if err := Write(&out, transcript, c.format); err != nil {
+			t.Fatalf("%s: %v", c.format, err)
+		}
+
+		for _, want := range c.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s: missing %q in\n%s", c.format, want, out.String())
+			}
+		}
+	}
+
+	if err := Write(&bytes.Buffer{}, transcript, "pdf"); err != ErrUnknownFormat {
+		t.Errorf("unknown format: got %v", err)
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, transcript, FORMAT_JSONL); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(transcript.Records) {
+		t.Fatalf("%d lines for %d records", len(lines), len(transcript.Records))
+	}
+
+	var record history.Record
+	if err := json.Unmarshal([]byte(lines[3]), &record); err != nil || record.ID != "4" || !record.IsPrivate() {
+		t.Errorf("last line read back as %+v, %v", record, err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteErrors(t *testing.T) {
+	for _, format := range FORMATS {
+		if err := Write(failingWriter{}, transcript, format); err == nil {
+			t.Errorf("%s: a failed write was reported as a success", format)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		name string
+		from string
+		to   string
+		want [2]string
+		err  bool
+	}{
+		{"dates", "2025-03-01", "2025-03-02", [2]string{"2025-03-01 00:00:00", "2025-03-02 23:59:59"}, false},
+		{"times", "2025-03-01 10:30", "2025-03-01T12:00", [2]string{"2025-03-01 10:30:00", "2025-03-01 12:00:00"}, false},
+		{"day before the end", "", "2025-03-02 12:00", [2]string{"2025-03-01 12:00:00", "2025-03-02 12:00:00"}, false},
+		{"backwards", "2025-03-05", "2025-03-01", [2]string{}, true},
+		{"not a date", "yesterday", "", [2]string{}, true},
+	}
+
+	for _, c := range cases {
+		from, to, err := ParseRange(c.from, c.to)
+		if (err != nil) != c.err {
+			t.Errorf("%s: error %v", c.name, err)
+			continue
+		}
+		if err == nil && (from.Format(time.DateTime) != c.want[0] || to.Format(time.DateTime) != c.want[1]) {
+			t.Errorf("%s: got %s to %s, want %s to %s", c.name, from.Format(time.DateTime), to.Format(time.DateTime), c.want[0], c.want[1])
+		}
+	}
+
+	if from, to, err := ParseRange("", ""); err != nil || to.Sub(from) != 24*time.Hour || time.Since(to) > time.Minute {
+		t.Errorf("the default range isn't the last 24 hours: %v to %v, %v", from, to, err)
+	}
+}
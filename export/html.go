@@ -0,0 +1,92 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/history"
+	"strings"
+	"time"
+)
+
+func writeHTMLNickname(b *strings.Builder, nickname string, color string) {
+	if nickname == "" {
+		b.WriteString("<strong>Everyone</strong> ")
+		return
+	}
+
+	// Nicknames are escaped when users join
+	fmt.Fprintf(b, `<strong><font color="%s">%s</font></strong> `, color, nickname)
+}
+
+// writeHTMLRecord mimics how the chat thread frame shows messages.
+func writeHTMLRecord(b *strings.Builder, r history.Record) {
+	if r.ReplyTo != nil {
+		fmt.Fprintf(b, `<font size="-1" color="#808080">&nbsp;&nbsp;&raquo;&nbsp;<strong>%s</strong>: <i>%s</i></font><br>`,
+			r.ReplyTo.Nickname, html.EscapeString(r.ReplyTo.Excerpt))
+	}
+
+	b.WriteString("[" + r.Time.Format("03:04:05 PM") + "] ")
+
+	if r.IsSystemMessage {
+		subject := fmt.Sprintf(`<strong><font color="%s">%s</font></strong>`, r.SubjectColor, r.Subject)
+		b.WriteString(strings.ReplaceAll(r.Message, "{nickname}", subject))
+		b.WriteString("<br>\n")
+		return
+	}
+
+	writeHTMLNickname(b, r.From, r.FromColor)
+	if r.IsPrivate() {
+		b.WriteString("<i>privately</i> ")
+	}
+	b.WriteString(speechModeLabels[r.SpeechMode] + " ")
+	writeHTMLNickname(b, r.To, r.ToColor)
+	b.WriteString(": ")
+
+	message := html.EscapeString(r.Message)
+
+	switch r.SpeechMode {
+	case chat.MODE_SCREAM_AT:
+		b.WriteString(`<font size="4"><strong>` + message + "</strong></font>")
+	case chat.MODE_WHISPER_TO:
+		b.WriteString(`<font size="2"><i>` + message + "</i></font>")
+	default:
+		b.WriteString(message)
+	}
+
+	b.WriteString("<br>\n")
+}
+
+func writeHTML(w io.Writer, t Transcript) error {
+	var b strings.Builder
+
+	title := html.EscapeString(t.RoomName) + " chat transcript"
+
+	b.WriteString("<!DOCTYPE HTML PUBLIC \"-//W3C//DTD HTML 3.2 Final//EN\">\n")
+	b.WriteString("<html>\n<head>\n<title>" + title + "</title>\n</head>\n")
+	b.WriteString("<body bgcolor=\"#EEEEEE\">\n")
+	fmt.Fprintf(&b, "<table width=\"100%%\" cellspacing=\"0\" cellpadding=\"4\" border=\"0\"><tr><td bgcolor=\"%s\">", t.Color)
+	fmt.Fprintf(&b, "<font face=\"Verdana,Arial\"><strong>%s</strong></font><br>", title)
+	fmt.Fprintf(&b, "<font face=\"Verdana,Arial\" size=\"-1\">%s to %s (UTC)</font>",
+		t.From.Format(time.DateTime), t.To.Format(time.DateTime))
+	b.WriteString("</td></tr></table>\n<br>\n")
+
+	day := ""
+	for _, r := range t.Records {
+		if d := r.Time.Format("Monday, January 2 2006"); d != day {
+			day = d
+			b.WriteString("<p><strong>" + day + "</strong></p>\n")
+		}
+		writeHTMLRecord(&b, r)
+	}
+
+	if len(t.Records) == 0 {
+		b.WriteString("<i>Nothing was said in this period.</i>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
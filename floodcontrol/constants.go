@@ -1,22 +1,29 @@
 package floodcontrol
 
+// Defaults for anything left out of the flood-control config
 const (
+	// Messages someone can send in a row before the limit kicks in
+	DEFAULT_BURST = 10
+	// How many messages per second they get back after that, together
+	// with the burst it's close to the old 10 messages in 5 seconds
+	DEFAULT_PER_SECOND float64 = 2
 	// how long the user has to wait to send
-	// a message after a flood in minutes
-	MESSAGE_FLOOD_COOLDOWN_SEC float64 = 120
-	// it's considered a flood if the number
-	// of messages reached MESSAGE_FLOOD_MESSAGES_COUNT
-	// within:
-	MESSAGE_FLOOD_RANGE_SEC float64 = 5
-	// it's considered a flood if the number
-	// of messages is this within x seconds
-	MESSAGE_FLOOD_MESSAGES_COUNT = 10
+	// a message after a flood in seconds
+	DEFAULT_COOLDOWN_SEC = 120
 	// this is the number of floods the user
 	// can do before they get blocked for a
 	// longer period.
-	MESSAGE_FLOOD_MAXIMUM_FLOODS = 4
+	DEFAULT_MAX_FLOODS = 4
 	// this is how long the user gets locked
 	// from sending messages if they flooded
 	// more than the number above.
-	MESSAGE_FLOOD_IP_BAN_MIN = 30
+	DEFAULT_BAN_MIN = 30
+	// One flood is forgiven for every this many
+	// minutes without a new one
+	DEFAULT_FLOOD_DECAY_MIN = 60
+	// IPs nobody has heard from in this long are forgotten
+	DEFAULT_IDLE_EVICTION_MIN = 60
 )
+
+// How often idle IPs are cleaned up
+const EVICTION_CHECK_SEC = 60
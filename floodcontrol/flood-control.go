@@ -1,22 +1,67 @@
 package floodcontrol
 
 import (
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/netblocks"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/samber/lo"
 )
 
 var (
 	mu  sync.Mutex
-	ips = map[string]*FloodControl{}
+	ips = map[string]*ipControl{}
 )
 
-func getControl(ip string) *FloodControl {
-	control, ok := ips[ip]
-	if !ok {
-		control = &FloodControl{}
-		ips[ip] = control
+func orDefault[T int | float64](values ...T) T {
+	for _, value := range values {
+		if value > 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+// SettingsFor returns the limits in the room, rooms can override any of
+// the global ones.
+func SettingsFor(roomId string) Settings {
+	global := config.Current.FloodControl
+	room, _ := lo.Find(config.Current.Rooms, func(r config.ConfigChatRoom) bool { return r.ID == roomId })
+	override := room.FloodControl
+
+	return Settings{
+		Burst:         orDefault(override.Burst, global.Burst, DEFAULT_BURST),
+		PerSecond:     orDefault(override.PerSecond, global.PerSecond, DEFAULT_PER_SECOND),
+		CooldownSec:   orDefault(override.CooldownSec, global.CooldownSec, DEFAULT_COOLDOWN_SEC),
+		MaxFloods:     orDefault(override.MaxFloods, global.MaxFloods, DEFAULT_MAX_FLOODS),
+		BanMin:        orDefault(override.BanMin, global.BanMin, DEFAULT_BAN_MIN),
+		FloodDecayMin: orDefault(override.FloodDecayMin, global.FloodDecayMin, DEFAULT_FLOOD_DECAY_MIN),
+	}
+}
+
+// lookup finds the IP without adding it, expects mu to be locked.
+func lookup(ip string) (*ipControl, bool) {
+	// IPv6 users count as one for their whole prefix
+	control, ok := ips[netblocks.GroupKey(ip)]
+	return control, ok
+}
+
+// decay forgives a flood for every quiet period since the last one.
+func (c *FloodControl) decay(now time.Time, settings Settings) {
+	period := time.Duration(settings.FloodDecayMin) * time.Minute
+	if c.FloodCount == 0 || period <= 0 {
+		return
 	}
-	return control
+
+	forgiven := int(now.Sub(c.LastFlood) / period)
+	if forgiven <= 0 {
+		return
+	}
+
+	c.FloodCount = max(0, c.FloodCount-forgiven)
+	c.LastFlood = c.LastFlood.Add(time.Duration(forgiven) * period)
 }
 
 // IsIPBanned returns true if the IP is in a ban period.
@@ -24,71 +69,148 @@ func IsIPBanned(ip string) bool {
 	mu.Lock()
 	defer mu.Unlock()
 
-	c := getControl(ip)
+	c, ok := lookup(ip)
 	// If current time is before the ban’s end, it’s banned
-	return time.Now().Before(c.BanEndTime)
+	return ok && time.Now().Before(c.BanEndTime)
 }
 
-// IsCooldownPeriod returns true if the IP is still in cooldown
-// (even if not fully banned).
-func IsCooldownPeriod(ip string) bool {
+// IsCooldownPeriod returns true if the IP is still in cooldown in the
+// room (even if not fully banned).
+func IsCooldownPeriod(ip string, roomId string) bool {
 	mu.Lock()
 	defer mu.Unlock()
 
-	c := getControl(ip)
+	c, ok := lookup(ip)
+	if !ok || c.Rooms[roomId] == nil {
+		return false
+	}
+
 	// If current time is before NextAllowedMessage, it’s on cooldown
-	return time.Now().Before(c.NextAllowedMessage)
+	return time.Now().Before(c.Rooms[roomId].NextAllowedMessage)
 }
 
 // RecordMessage should be called whenever the user sends a message.
-// This function updates the IP’s sliding window, checks for flooding,
-// and, if needed, sets cooldown or triggers a ban.
-func RecordMessage(ip string) {
+// Each message takes a token from the IP's bucket in the room, running
+// out is a flood, which sets a cooldown or, after too many, a ban.
+func RecordMessage(ip string, roomId string) {
+	settings := SettingsFor(roomId)
+
 	mu.Lock()
 	defer mu.Unlock()
 
-	c := getControl(ip)
-
 	now := time.Now()
+	key := netblocks.GroupKey(ip)
+
+	ipc, ok := ips[key]
+	if !ok {
+		ipc = &ipControl{Rooms: map[string]*FloodControl{}}
+		ips[key] = ipc
+	}
 
-	// If we’re past NextAllowedMessage, it means cooldown has expired
+	c, ok := ipc.Rooms[roomId]
+	if !ok {
+		c = &FloodControl{Tokens: float64(settings.Burst), LastRefill: now}
+		ipc.Rooms[roomId] = c
+	}
+
+	c.LastSeen = now
+	c.decay(now, settings)
+
+	// Refill for the time since the last message, never past the burst
+	c.Tokens = min(float64(settings.Burst), c.Tokens+now.Sub(c.LastRefill).Seconds()*settings.PerSecond)
+	c.LastRefill = now
+
+	// Attempts during a cooldown still use up tokens, so spamming
+	// through it counts toward further floods.
+	if c.Tokens >= 1 {
+		c.Tokens--
+		return
+	}
+
+	// Only increment FloodCount if we’re not currently in cooldown
 	if now.After(c.NextAllowedMessage) {
-		c.NextAllowedMessage = time.Time{}
+		c.FloodCount++
+		c.LastFlood = now
 	}
 
-	// 1) If currently banned, do nothing else.
-	// 2) If currently in cooldown, do nothing else.
-	// The chat code can still reject the message, but we keep track anyway:
-	// because even if they're in cooldown, they might spam attempts that count
-	// toward further bans. For your usage, you might want to handle differently.
-
-	// Clean up old timestamps from the sliding window
-	cutoff := now.Add(-time.Duration(MESSAGE_FLOOD_RANGE_SEC) * time.Second)
-	i := 0
-	for i < len(c.RecentMessages) && c.RecentMessages[i].Before(cutoff) {
-		i++
+	// If user’s floods exceed maximum, ban them
+	if c.FloodCount >= settings.MaxFloods {
+		ipc.BanEndTime = now.Add(time.Duration(settings.BanMin) * time.Minute)
+		c.FloodCount = 0
 	}
-	c.RecentMessages = c.RecentMessages[i:] // drop old messages
 
-	// Add this message to the list
-	c.RecentMessages = append(c.RecentMessages, now)
+	// Always set the new cooldown window
+	c.NextAllowedMessage = now.Add(time.Duration(settings.CooldownSec) * time.Second)
+}
 
-	// Check if it’s a flood
-	if len(c.RecentMessages) >= (MESSAGE_FLOOD_MESSAGES_COUNT + 1) {
-		// We triggered a flood → set cooldown
+// GetState returns how the IP is doing, if it sent anything lately.
+func GetState(ip string) (State, bool) {
+	mu.Lock()
+	defer mu.Unlock()
 
-		// Only increment FloodCount if we’re not currently in cooldown
-		if c.NextAllowedMessage.IsZero() || now.After(c.NextAllowedMessage) {
-			c.FloodCount++
-		}
+	c, ok := lookup(ip)
+	if !ok {
+		return State{}, false
+	}
+
+	now := time.Now()
+	rooms := make([]RoomState, 0, len(c.Rooms))
+	for roomId, room := range c.Rooms {
+		room.decay(now, SettingsFor(roomId))
+
+		rooms = append(rooms, RoomState{
+			RoomID:        roomId,
+			Tokens:        room.Tokens,
+			FloodCount:    room.FloodCount,
+			LastSeen:      room.LastSeen,
+			CooldownUntil: room.NextAllowedMessage,
+		})
+	}
+
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].RoomID < rooms[j].RoomID })
+
+	return State{
+		Key:         netblocks.GroupKey(ip),
+		BannedUntil: c.BanEndTime,
+		Rooms:       rooms,
+	}, true
+}
 
-		// If user’s floods exceed maximum, ban them
-		if c.FloodCount >= MESSAGE_FLOOD_MAXIMUM_FLOODS {
-			c.BanEndTime = now.Add(time.Duration(MESSAGE_FLOOD_IP_BAN_MIN) * time.Minute)
-			c.FloodCount = 0
+// Reset forgets the IP, lifting its cooldowns and flood ban.
+func Reset(ip string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := netblocks.GroupKey(ip)
+	_, ok := ips[key]
+	delete(ips, key)
+	return ok
+}
+
+// EvictIdle forgets rooms an IP has been quiet in for a while and isn't
+// cooling down in, then IPs with no rooms left that aren't banned,
+// returns how many IPs were dropped.
+func EvictIdle() int {
+	idle := time.Duration(orDefault(config.Current.FloodControl.IdleEvictionMin, DEFAULT_IDLE_EVICTION_MIN)) * time.Minute
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	evicted := 0
+
+	for key, ipc := range ips {
+		for roomId, c := range ipc.Rooms {
+			if now.Sub(c.LastSeen) > idle && now.After(c.NextAllowedMessage) {
+				delete(ipc.Rooms, roomId)
+			}
 		}
 
-		// Always set the new cooldown window
-		c.NextAllowedMessage = now.Add(time.Duration(MESSAGE_FLOOD_COOLDOWN_SEC) * time.Second)
+		if len(ipc.Rooms) == 0 && now.After(ipc.BanEndTime) {
+			delete(ips, key)
+			evicted++
+		}
 	}
+
+	return evicted
 }
@@ -0,0 +1,164 @@
+package floodcontrol
+
+import (
+	"retro-chat-rooms/config"
+	"testing"
+	"time"
+)
+
+func setup(settings config.FloodControlConfig) {
+	mu.Lock()
+	defer mu.Unlock()
+	ips = map[string]*ipControl{}
+	config.Current.FloodControl = settings
+	config.Current.Rooms = nil
+}
+
+func send(ip string, roomId string, count int) {
+	for i := 0; i < count; i++ {
+		RecordMessage(ip, roomId)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	cases := []struct {
+		name     string
+		sent     int
+		cooldown bool
+	}{
+		{"within the burst", 5, false},
+		{"last of the burst", 6, false},
+		{"past the burst", 7, true},
+	}
+
+	for _, c := range cases {
+		// Nothing refills while the test runs
+		setup(config.FloodControlConfig{Burst: 6, PerSecond: 0.0001})
+
+		send("192.0.2.1", "general", c.sent)
+		if got := IsCooldownPeriod("192.0.2.1", "general"); got != c.cooldown {
+			t.Errorf("%s: cooldown is %v, want %v", c.name, got, c.cooldown)
+		}
+		if IsIPBanned("192.0.2.1") {
+			t.Errorf("%s: banned after a single flood", c.name)
+		}
+	}
+}
+
+func TestRoomsAreIndependent(t *testing.T) {
+	setup(config.FloodControlConfig{Burst: 3, PerSecond: 0.0001})
+
+	send("192.0.2.1", "general", 4)
+
+	if !IsCooldownPeriod("192.0.2.1", "general") {
+		t.Fatal("no cooldown after flooding")
+	}
+	if IsCooldownPeriod("192.0.2.1", "random") {
+		t.Error("flooding one room put another on cooldown")
+	}
+
+	send("192.0.2.1", "random", 3)
+	if IsCooldownPeriod("192.0.2.1", "random") {
+		t.Error("the other room didn't get its own burst")
+	}
+}
+
+func TestFloodBan(t *testing.T) {
+	setup(config.FloodControlConfig{Burst: 1, PerSecond: 0.0001, MaxFloods: 2})
+
+	send("192.0.2.1", "general", 2)
+	if IsIPBanned("192.0.2.1") {
+		t.Fatal("banned after the first flood")
+	}
+
+	// Floods only count once the cooldown is over
+	ips["192.0.2.1"].Rooms["general"].NextAllowedMessage = time.Now().Add(-time.Second)
+	send("192.0.2.1", "general", 1)
+
+	if !IsIPBanned("192.0.2.1") {
+		t.Error("not banned after the maximum floods")
+	}
+}
+
+func TestFloodDecay(t *testing.T) {
+	cases := []struct {
+		name  string
+		quiet time.Duration
+		want  int
+	}{
+		{"too soon", 30 * time.Minute, 3},
+		{"one period", 61 * time.Minute, 2},
+		{"two periods", 125 * time.Minute, 1},
+		{"long gone", 24 * time.Hour, 0},
+	}
+
+	for _, c := range cases {
+		setup(config.FloodControlConfig{Burst: 1, PerSecond: 0.0001, MaxFloods: 10, FloodDecayMin: 60})
+
+		send("192.0.2.1", "general", 1)
+		room := ips["192.0.2.1"].Rooms["general"]
+		room.FloodCount = 3
+		room.LastFlood = time.Now().Add(-c.quiet)
+
+		state, _ := GetState("192.0.2.1")
+		if got := state.Rooms[0].FloodCount; got != c.want {
+			t.Errorf("%s: %d floods left, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestIPv6Grouping(t *testing.T) {
+	setup(config.FloodControlConfig{Burst: 2, PerSecond: 0.0001})
+
+	send("2001:db8:1::1", "general", 1)
+	send("2001:db8:1::2", "general", 2)
+
+	if !IsCooldownPeriod("2001:db8:1::ffff", "general") {
+		t.Error("addresses in the same /64 don't share a bucket")
+	}
+	if IsCooldownPeriod("2001:db8:2::1", "general") {
+		t.Error("another /64 shares the bucket")
+	}
+}
+
+func TestEvictIdle(t *testing.T) {
+	setup(config.FloodControlConfig{Burst: 1, PerSecond: 0.0001, IdleEvictionMin: 1})
+
+	send("192.0.2.1", "general", 1)
+	send("192.0.2.2", "general", 2)
+	send("192.0.2.3", "general", 1)
+
+	old := time.Now().Add(-time.Hour)
+	ips["192.0.2.1"].Rooms["general"].LastSeen = old
+	// Cooling down, so it has to stay
+	ips["192.0.2.2"].Rooms["general"].LastSeen = old
+
+	if evicted := EvictIdle(); evicted != 1 {
+		t.Errorf("evicted %d IPs, want 1", evicted)
+	}
+	if _, found := GetState("192.0.2.1"); found {
+		t.Error("the idle IP is still tracked")
+	}
+	if _, found := GetState("192.0.2.2"); !found {
+		t.Error("the IP cooling down was evicted")
+	}
+	if _, found := GetState("192.0.2.3"); !found {
+		t.Error("the active IP was evicted")
+	}
+}
+
+func TestReset(t *testing.T) {
+	setup(config.FloodControlConfig{Burst: 1, PerSecond: 0.0001, MaxFloods: 1})
+
+	send("192.0.2.1", "general", 2)
+	if !IsIPBanned("192.0.2.1") {
+		t.Fatal("not banned")
+	}
+
+	if !Reset("192.0.2.1") {
+		t.Error("reset found nothing")
+	}
+	if IsIPBanned("192.0.2.1") || IsCooldownPeriod("192.0.2.1", "general") {
+		t.Error("still limited after a reset")
+	}
+}
@@ -2,10 +2,48 @@ package floodcontrol
 
 import "time"
 
+// FloodControl tracks one IP, or IPv6 prefix, in one room.
 type FloodControl struct {
-	// Timestamps for the last few messages (sliding window)
-	RecentMessages     []time.Time
+	// Messages left in the bucket, it refills over time up to the burst
+	Tokens     float64
+	LastRefill time.Time
+	LastSeen   time.Time
+
 	NextAllowedMessage time.Time // If in cooldown, user can’t talk until this
-	BanEndTime         time.Time // If banned, user can’t talk until this
 	FloodCount         int       // How many times this IP has triggered a flood
+	LastFlood          time.Time // Floods are forgiven one by one after this
+}
+
+// ipControl is everything known about an IP, its rooms are limited
+// separately but a flood ban keeps it out of all of them.
+type ipControl struct {
+	Rooms      map[string]*FloodControl
+	BanEndTime time.Time // If banned, user can’t talk until this
+}
+
+// Settings are the limits for one room.
+type Settings struct {
+	Burst         int
+	PerSecond     float64
+	CooldownSec   int
+	MaxFloods     int
+	BanMin        int
+	FloodDecayMin int
+}
+
+// State is what moderators see about an IP.
+type State struct {
+	// The IP or, for IPv6, the prefix it's tracked as
+	Key         string
+	BannedUntil time.Time
+	// Rooms it talked in lately, by ID
+	Rooms []RoomState
+}
+
+type RoomState struct {
+	RoomID        string
+	Tokens        float64
+	FloodCount    int
+	LastSeen      time.Time
+	CooldownUntil time.Time
 }
@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Letters from other scripts that look like latin ones
+var homoglyphs = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o',
+	'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ї': 'i', 'ј': 'j',
+	'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	// Greek
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o',
+	'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	// Latin letters that lowercase or decompose into something else
+	'ı': 'i', 'ł': 'l', 'ø': 'o', 'đ': 'd', 'ß': 's',
+}
+
+// FoldRune appends the plain lowercase letters r looks like to folded,
+// fullwidth letters, accents and other scripts included.
+func FoldRune(r rune, folded []rune) []rune {
+	// Most text is plain ASCII, which has nothing to decompose
+	if r < utf8.RuneSelf {
+		return append(folded, unicode.ToLower(r))
+	}
+
+	for _, d := range norm.NFKD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
+			continue
+		}
+
+		d = unicode.ToLower(d)
+		if look, found := homoglyphs[d]; found {
+			d = look
+		}
+		folded = append(folded, d)
+	}
+
+	return folded
+}
@@ -0,0 +1,4 @@
+package history
+
+// Daily logs are named after the UTC date
+const DAY_FORMAT = "2006-01-02"
@@ -0,0 +1,138 @@
+package history
+
+import (
+	"encoding/json"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/storage"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// History is kept in one log per room and day, so a query only
+// reads the days it asks for.
+func roomDir(roomId string) string {
+	return "history/" + roomId
+}
+
+func logName(roomId string, t time.Time) string {
+	return roomDir(roomId) + "/" + t.UTC().Format(DAY_FORMAT)
+}
+
+// logsBetween lists the room's daily logs from the day of from to the day of to.
+func logsBetween(roomId string, from time.Time, to time.Time) ([]string, error) {
+	names, err := storage.Current.Logs(roomDir(roomId))
+	if err != nil {
+		return nil, err
+	}
+
+	first, last := logName(roomId, from), logName(roomId, to)
+	return lo.Filter(names, func(name string, _ int) bool {
+		return name >= first && name <= last
+	}), nil
+}
+
+func NewRecord(m *chat.ChatMessage) Record {
+	record := Record{
+		ID:              m.ID,
+		RoomID:          m.RoomID,
+		Time:            m.Time,
+		Privately:       m.Privately,
+		SpeechMode:      m.SpeechMode,
+		IsSystemMessage: m.IsSystemMessage,
+		Message:         m.Message,
+		Source:          m.Source,
+		ReplyTo:         m.ReplyTo,
+		ToID:            m.To,
+	}
+
+	if from := m.GetFrom(); from != nil {
+		record.FromID = from.ID
+		record.From = from.Nickname
+		record.FromColor = from.Color
+	}
+
+	if to := m.GetTo(); to != nil {
+		record.To = to.Nickname
+		record.ToColor = to.Color
+	}
+
+	if m.SystemMessageSubject != nil {
+		record.Subject = m.SystemMessageSubject.Nickname
+		record.SubjectColor = m.SystemMessageSubject.Color
+	}
+
+	return record
+}
+
+// Save appends the message to its room's stored history.
+func Save(m *chat.ChatMessage) error {
+	return storage.Current.Append(logName(m.RoomID, m.Time), NewRecord(m))
+}
+
+// Query returns the room's messages sent between from and to.
+func Query(roomId string, from time.Time, to time.Time, includePrivate bool) ([]Record, error) {
+	records := make([]Record, 0)
+
+	names, err := logsBetween(roomId, from, to)
+	if err != nil {
+		return records, err
+	}
+
+	for _, name := range names {
+		if err := scanDay(name, from, to, includePrivate, &records); err != nil {
+			return records, err
+		}
+	}
+
+	return records, nil
+}
+
+func scanDay(name string, from time.Time, to time.Time, includePrivate bool, records *[]Record) error {
+	return storage.Current.Scan(name, func(data []byte) error {
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			// A broken line shouldn't make the whole history unreadable
+			return nil
+		}
+
+		if record.Time.Before(from) || record.Time.After(to) {
+			return nil
+		}
+
+		if record.IsPrivate() && !includePrivate {
+			return nil
+		}
+
+		*records = append(*records, record)
+		return nil
+	})
+}
+
+// Remove deletes the messages from the room's history, since is when
+// the oldest of them was sent.
+func Remove(roomId string, ids []string, since time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	names, err := logsBetween(roomId, since, time.Now())
+	if err != nil {
+		return err
+	}
+
+	removed := lo.SliceToMap(ids, func(id string) (string, bool) { return id, true })
+
+	for _, name := range names {
+		err := storage.Current.Rewrite(name, func(data []byte) bool {
+			var record Record
+			// Keep what can't be read, Query skips it anyway
+			return json.Unmarshal(data, &record) != nil || !removed[record.ID]
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package history
+
+import (
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/storage"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+var day = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func backends(t *testing.T) map[string]storage.Backend {
+	return map[string]storage.Backend{
+		"file":   storage.NewFileBackend(t.TempDir()),
+		"memory": storage.NewMemoryBackend(),
+	}
+}
+
+func save(t *testing.T, id string, at time.Time, private bool) {
+	m := &chat.ChatMessage{ID: id, RoomID: "general", Time: at, Message: id}
+	if private {
+		m.Privately = true
+		m.To = "general-someone"
+	}
+	if err := Save(m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ids(records []Record) []string {
+	return lo.Map(records, func(r Record, _ int) string { return r.ID })
+}
+
+func TestQuery(t *testing.T) {
+	for name, backend := range backends(t) {
+		storage.Current = backend
+
+		save(t, "a", day.Add(-time.Hour), false)
+		save(t, "b", day.Add(time.Hour), false)
+		save(t, "c", day.Add(2*time.Hour), true)
+		save(t, "d", day.Add(25*time.Hour), false)
+		save(t, "e", day.Add(49*time.Hour), false)
+
+		cases := []struct {
+			name    string
+			from    time.Time
+			to      time.Time
+			private bool
+			want    []string
+		}{
+			{"one day", day, day.Add(24*time.Hour - time.Second), false, []string{"b"}},
+			{"private included", day, day.Add(24*time.Hour - time.Second), true, []string{"b", "c"}},
+			{"across days", day.Add(-2 * time.Hour), day.Add(26 * time.Hour), false, []string{"a", "b", "d"}},
+			{"within the hour", day.Add(30 * time.Minute), day.Add(90 * time.Minute), false, []string{"b"}},
+			{"nothing that day", day.Add(-72 * time.Hour), day.Add(-48 * time.Hour), false, []string{}},
+		}
+
+		for _, c := range cases {
+			records, err := Query("general", c.from, c.to, c.private)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", name, c.name, err)
+			}
+			if got := ids(records); !slices.Equal(got, c.want) {
+				t.Errorf("%s/%s: got %v, want %v", name, c.name, got, c.want)
+			}
+		}
+
+		if records, _ := Query("other", day.Add(-72*time.Hour), day.Add(72*time.Hour), true); len(records) != 0 {
+			t.Errorf("%s: another room got %v", name, ids(records))
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	for name, backend := range backends(t) {
+		storage.Current = backend
+
+		save(t, "a", day.Add(-time.Hour), false)
+		save(t, "b", day.Add(time.Hour), false)
+		save(t, "c", day.Add(2*time.Hour), false)
+		save(t, "d", day.Add(25*time.Hour), false)
+
+		if err := Remove("general", []string{"a", "b", "d"}, day.Add(time.Hour)); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		records, err := Query("general", day.Add(-48*time.Hour), time.Now(), true)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		// a was sent before since, so it stays
+		if got := ids(records); !slices.Equal(got, []string{"a", "c"}) {
+			t.Errorf("%s: got %v after removing, want [a c]", name, got)
+		}
+	}
+}
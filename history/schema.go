@@ -0,0 +1,32 @@
+package history
+
+import (
+	"retro-chat-rooms/chat"
+	"time"
+)
+
+// Record is how a chat message is kept in the stored history.
+type Record struct {
+	ID              string              `json:"id"`
+	RoomID          string              `json:"room"`
+	Time            time.Time           `json:"time"`
+	FromID          string              `json:"from_id,omitempty"`
+	From            string              `json:"from,omitempty"`
+	FromColor       string              `json:"from_color,omitempty"`
+	ToID            string              `json:"to_id,omitempty"`
+	To              string              `json:"to,omitempty"`
+	ToColor         string              `json:"to_color,omitempty"`
+	Privately       bool                `json:"privately"`
+	SpeechMode      string              `json:"speech_mode"`
+	IsSystemMessage bool                `json:"system"`
+	Subject         string              `json:"subject,omitempty"`
+	SubjectColor    string              `json:"subject_color,omitempty"`
+	Message         string              `json:"message"`
+	Source          string              `json:"source,omitempty"`
+	ReplyTo         *chat.QuotedMessage `json:"reply_to,omitempty"`
+}
+
+// IsPrivate tells if the message was meant for a single person.
+func (r Record) IsPrivate() bool {
+	return r.Privately && r.ToID != ""
+}
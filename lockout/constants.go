@@ -0,0 +1,12 @@
+package lockout
+
+const (
+	// Failed logins counted within this many minutes
+	FAILURE_WINDOW_MIN = 15
+	// Wrong passwords for one account before it's locked
+	MAX_ACCOUNT_FAILURES = 5
+	// Wrong passwords from one IP, for any account, before it's locked
+	MAX_IP_FAILURES = 10
+	// How long a lockout lasts
+	LOCKOUT_MIN = 15
+)
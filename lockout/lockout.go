@@ -0,0 +1,112 @@
+package lockout
+
+import (
+	"retro-chat-rooms/netblocks"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	mu        sync.Mutex
+	byIP      = map[string]*attempts{}
+	byAccount = map[string]*attempts{}
+)
+
+func get(list map[string]*attempts, key string) *attempts {
+	a, ok := list[key]
+	if !ok {
+		a = &attempts{}
+		list[key] = a
+	}
+	return a
+}
+
+func accountKey(account string) string {
+	return strings.ToLower(strings.TrimSpace(account))
+}
+
+// Remaining returns how long until the IP or account can try again.
+func Remaining(ip string, account string) (time.Duration, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	remaining := time.Duration(0)
+
+	for _, a := range []*attempts{byIP[netblocks.GroupKey(ip)], byAccount[accountKey(account)]} {
+		if a != nil && a.LockedUntil.Sub(now) > remaining {
+			remaining = a.LockedUntil.Sub(now)
+		}
+	}
+
+	return remaining, remaining > 0
+}
+
+// fail counts a failure and returns true if it caused a lockout,
+// expects mu to be locked.
+func fail(a *attempts, max int, now time.Time) bool {
+	cutoff := now.Add(-FAILURE_WINDOW_MIN * time.Minute)
+	i := 0
+	for i < len(a.Failures) && a.Failures[i].Before(cutoff) {
+		i++
+	}
+	a.Failures = append(a.Failures[i:], now)
+
+	if len(a.Failures) < max {
+		return false
+	}
+
+	a.Failures = nil
+	a.LockedUntil = now.Add(LOCKOUT_MIN * time.Minute)
+	return true
+}
+
+// RecordFailure counts a wrong password, returns true if the IP
+// or the account got locked because of it.
+func RecordFailure(ip string, account string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+
+	ipLocked := fail(get(byIP, netblocks.GroupKey(ip)), MAX_IP_FAILURES, now)
+	accountLocked := fail(get(byAccount, accountKey(account)), MAX_ACCOUNT_FAILURES, now)
+
+	return ipLocked || accountLocked
+}
+
+// RecordSuccess forgets the failures of the account and IP.
+func RecordSuccess(ip string, account string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	delete(byIP, netblocks.GroupKey(ip))
+	delete(byAccount, accountKey(account))
+}
+
+func isExpired(a *attempts, now time.Time) bool {
+	cutoff := now.Add(-FAILURE_WINDOW_MIN * time.Minute)
+	return now.After(a.LockedUntil) && (len(a.Failures) == 0 || a.Failures[len(a.Failures)-1].Before(cutoff))
+}
+
+// EvictExpired forgets IPs and accounts that aren't locked and have
+// no failures left in the window, returns how many were dropped.
+func EvictExpired() int {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	evicted := 0
+
+	for _, list := range []map[string]*attempts{byIP, byAccount} {
+		for key, a := range list {
+			if isExpired(a, now) {
+				delete(list, key)
+				evicted++
+			}
+		}
+	}
+
+	return evicted
+}
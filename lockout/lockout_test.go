@@ -0,0 +1,139 @@
+package lockout
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	byIP = map[string]*attempts{}
+	byAccount = map[string]*attempts{}
+}
+
+func TestAccountLockout(t *testing.T) {
+	reset()
+
+	for i := 1; i < MAX_ACCOUNT_FAILURES; i++ {
+		if RecordFailure(fmt.Sprintf("192.0.2.%d", i), "Mod") {
+			t.Fatalf("locked after %d failures", i)
+		}
+	}
+
+	if !RecordFailure("192.0.2.99", "mod ") {
+		t.Fatal("not locked after the last allowed failure")
+	}
+
+	if _, locked := Remaining("198.51.100.1", "MOD"); !locked {
+		t.Error("the account can be tried from another IP while locked")
+	}
+	if _, locked := Remaining("198.51.100.1", "owner"); locked {
+		t.Error("another account got locked")
+	}
+}
+
+func TestIPLockout(t *testing.T) {
+	reset()
+
+	for i := 1; i < MAX_IP_FAILURES; i++ {
+		if RecordFailure("192.0.2.1", fmt.Sprintf("account%d", i)) {
+			t.Fatalf("locked after %d failures", i)
+		}
+	}
+
+	if !RecordFailure("192.0.2.1", "another") {
+		t.Fatal("not locked after the last allowed failure")
+	}
+
+	if remaining, locked := Remaining("192.0.2.1", "fresh"); !locked || remaining > LOCKOUT_MIN*time.Minute {
+		t.Errorf("Remaining() = %v, %v, want locked for up to %d minutes", remaining, locked, LOCKOUT_MIN)
+	}
+	if _, locked := Remaining("192.0.2.2", "fresh"); locked {
+		t.Error("another IP got locked")
+	}
+}
+
+func TestIPv6PrefixLockout(t *testing.T) {
+	reset()
+
+	// Moving around the /64 doesn't get more tries
+	for i := 0; i < MAX_IP_FAILURES; i++ {
+		RecordFailure(fmt.Sprintf("2001:db8:1:2::%x", i), fmt.Sprintf("account%d", i))
+	}
+
+	if _, locked := Remaining("2001:db8:1:2:ffff::1", "fresh"); !locked {
+		t.Error("the rest of the /64 isn't locked")
+	}
+	if _, locked := Remaining("2001:db8:1:3::1", "fresh"); locked {
+		t.Error("another /64 got locked")
+	}
+}
+
+func TestOldFailuresDontCount(t *testing.T) {
+	reset()
+
+	for i := 1; i < MAX_ACCOUNT_FAILURES; i++ {
+		RecordFailure("192.0.2.1", "mod")
+	}
+
+	mu.Lock()
+	for i := range byAccount["mod"].Failures {
+		byAccount["mod"].Failures[i] = time.Now().Add(-(FAILURE_WINDOW_MIN + 1) * time.Minute)
+	}
+	mu.Unlock()
+
+	if RecordFailure("192.0.2.1", "mod") {
+		t.Error("failures from outside the window counted")
+	}
+}
+
+func TestRecordSuccess(t *testing.T) {
+	reset()
+
+	for i := 1; i < MAX_ACCOUNT_FAILURES; i++ {
+		RecordFailure("192.0.2.1", "mod")
+	}
+	RecordSuccess("192.0.2.1", "mod")
+
+	if RecordFailure("192.0.2.1", "mod") {
+		t.Error("failures before the successful login counted")
+	}
+}
+
+func TestEvictExpired(t *testing.T) {
+	reset()
+
+	RecordFailure("192.0.2.1", "recent")
+	for i := 0; i < MAX_ACCOUNT_FAILURES; i++ {
+		RecordFailure("192.0.2.2", "locked")
+	}
+	RecordFailure("192.0.2.3", "old")
+
+	mu.Lock()
+	old := time.Now().Add(-(FAILURE_WINDOW_MIN + 1) * time.Minute)
+	byIP["192.0.2.3"].Failures = []time.Time{old}
+	byAccount["old"].Failures = []time.Time{old}
+	// Locked a while ago, it's over
+	byAccount["expired"] = &attempts{LockedUntil: time.Now().Add(-time.Minute)}
+	mu.Unlock()
+
+	if evicted := EvictExpired(); evicted != 3 {
+		t.Errorf("EvictExpired() = %d, want 3", evicted)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, key := range []string{"recent", "locked"} {
+		if _, found := byAccount[key]; !found {
+			t.Errorf("the %s account was evicted", key)
+		}
+	}
+	for _, key := range []string{"old", "expired"} {
+		if _, found := byAccount[key]; found {
+			t.Errorf("the %s account wasn't evicted", key)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package lockout
+
+import "time"
+
+type attempts struct {
+	Failures    []time.Time
+	LockedUntil time.Time
+}
@@ -4,8 +4,12 @@ import (
 	"encoding/gob"
 	"fmt"
 	"log"
+	"os"
 	"retro-chat-rooms/api"
+	"retro-chat-rooms/audit"
 	"retro-chat-rooms/chat"
+	"retro-chat-rooms/cli"
+	"retro-chat-rooms/config"
 	"retro-chat-rooms/discord"
 	"retro-chat-rooms/profanity"
 	"retro-chat-rooms/routes"
@@ -27,6 +31,11 @@ func routeWithSession(fn func(ctx *gin.Context, session sessions.Session)) func(
 }
 
 func main() {
+	// Admin commands, ex: retro-chat-rooms export -room general
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
+
 	gob.Register(map[string]string{})
 
 	profanity.LoadProfanityFilters()
@@ -35,13 +44,27 @@ func main() {
 
 	// Background Tasks
 	go tasks.CheckUserStatus()
+	go tasks.ClosePolls()
+	go tasks.ReloadWordFilters()
+	go tasks.ReloadIPBlocklists()
+	go tasks.EvictExpired()
 	tasks.ObserveMessagesToDiscord()
+	tasks.ObserveMessagesToHistory()
+	tasks.ObserveMessagesToLogs()
+	audit.RecordModerationEvents()
+	go tasks.MirrorAuditToDiscord()
+	go tasks.MirrorReportsToDiscord()
 	discord.Instance.Connect()
 	discord.Instance.OnReceiveMessage(tasks.OnReceiveDiscordMessage)
 
 	router := gin.Default()
 
-	store := cookie.NewStore([]byte("secret1"))
+	// Anyone who knows the key can write their own session cookie
+	if len(config.Current.SessionSecret) < config.MIN_SESSION_SECRET_LENGTH {
+		log.Fatalf("session-secret in config.yaml needs at least %d characters, make one with: openssl rand -hex 32", config.MIN_SESSION_SECRET_LENGTH)
+	}
+
+	store := cookie.NewStore([]byte(config.Current.SessionSecret))
 	store.Options(sessions.Options{
 		Path:     "/",
 		MaxAge:   86400 * 365,
@@ -60,7 +83,8 @@ func main() {
 	router.GET("/join/:id", routeWithSession(routes.GetJoin))
 	router.POST("/join/:id", routeWithSession(routes.PostJoin))
 	router.POST("/logout", routeWithSession(routes.PostLogout))
-	router.GET("/admin-login", routes.GetAdminLogin)
+	router.GET("/admin-login", routeWithSession(routes.GetAdminLogin))
+	router.GET("/captcha/:id", routes.GetCaptcha)
 	router.POST("/admin-login", routeWithSession(routes.PostAdminLogin))
 
 	// Main chat Screen
@@ -71,6 +95,33 @@ func main() {
 	router.GET("/chat-talk/:id", routeWithSession(routes.GetChatTalk))
 	router.POST("/chat-talk/:id", routeWithSession(routes.PostChatTalk))
 	router.GET("/chat-users/:id", routeWithSession(routes.GetChatUsers))
+	router.POST("/vote/:id", routeWithSession(routes.PostVote))
+	router.GET("/chat-moderate/:id", routeWithSession(routes.GetChatModerate))
+	router.POST("/chat-moderate/:id", routeWithSession(routes.PostChatModerate))
+	router.GET("/chat-report/:id", routeWithSession(routes.GetChatReport))
+	router.POST("/chat-report/:id", routeWithSession(routes.PostChatReport))
+	router.GET("/kicked/:id", routeWithSession(routes.GetKicked))
+
+	// Private messages window
+	router.GET("/private/:id", routeWithSession(routes.GetPrivate))
+	router.GET("/private-thread/:id", routeWithSession(routes.GetPrivateThread))
+	router.GET("/private-talk/:id", routeWithSession(routes.GetPrivateTalk))
+	router.POST("/private-talk/:id", routeWithSession(routes.PostPrivateTalk))
+
+	// Admin
+	router.GET("/admin", routeWithSession(routes.GetAdmin))
+	router.POST("/admin/action", routeWithSession(routes.PostAdminAction))
+	router.POST("/admin/rooms", routeWithSession(routes.PostAdminRoom))
+	router.POST("/admin/bans", routeWithSession(routes.PostAdminBan))
+	router.POST("/admin/bans/remove", routeWithSession(routes.PostAdminUnban))
+	router.POST("/admin/flood/reset", routeWithSession(routes.PostAdminFloodReset))
+	router.GET("/admin/reports", routeWithSession(routes.GetAdminReports))
+	router.POST("/admin/reports/resolve", routeWithSession(routes.PostAdminReportResolve))
+	router.GET("/admin/audit", routeWithSession(routes.GetAdminAudit))
+	router.GET("/admin/filters", routeWithSession(routes.GetAdminFilters))
+	router.POST("/admin/filters/add", routeWithSession(routes.PostAdminFilterAdd))
+	router.POST("/admin/filters/remove", routeWithSession(routes.PostAdminFilterRemove))
+	router.GET("/admin/export/:id", routeWithSession(routes.GetAdminExport))
 
 	// API
 	group := router.Group("/api")
@@ -0,0 +1,8 @@
+package moderation
+
+const (
+	DEFAULT_BAN_MIN  = 60
+	DEFAULT_MUTE_MIN = 10
+	// A day, longer bans should go in a blocklist
+	MAX_BAN_MIN = 1440
+)
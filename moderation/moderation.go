@@ -0,0 +1,205 @@
+package moderation
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"retro-chat-rooms/bans"
+	"retro-chat-rooms/chat"
+	"time"
+)
+
+func announce(target chat.ChatUser, message string) {
+	chat.SendMessage(&chat.ChatMessage{
+		RoomID:               target.RoomId,
+		Time:                 time.Now().UTC(),
+		Message:              message,
+		IsSystemMessage:      true,
+		SystemMessageSubject: &target,
+		Privately:            false,
+		SpeechMode:           chat.MODE_SAY_TO,
+		From:                 target.ID,
+		ShowClientIcon:       false,
+		InvolvedUsers:        []chat.ChatUser{target},
+	})
+}
+
+func describeReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " (" + template.HTMLEscapeString(reason) + ")"
+}
+
+func Kick(actor string, target chat.ChatUser, reason string) {
+	announce(target, "{nickname} was kicked out by "+actor+describeReason(reason)+".")
+
+	message := "You have been kicked out by a moderator."
+	if reason != "" {
+		message = "You have been kicked out: " + reason
+	}
+
+	chat.KickUser(target.ID, message)
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_KICK,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+		Reason: reason,
+	})
+}
+
+// Ban keeps the user out by every means we know them by: IP,
+// web session and Discord ID.
+func Ban(actor string, target chat.ChatUser, reason string, duration time.Duration) {
+	issuedBy := html.UnescapeString(actor)
+
+	if target.IP != "" {
+		bans.Add(bans.TYPE_IP, target.IP, reason, issuedBy, duration)
+	}
+
+	if target.Fingerprint != "" {
+		bans.Add(bans.TYPE_FINGERPRINT, target.Fingerprint, reason, issuedBy, duration)
+	}
+
+	if target.IsDiscordUser() {
+		bans.Add(bans.TYPE_DISCORD, target.DiscordId, reason, issuedBy, duration)
+	}
+
+	announce(target, "{nickname} was banned by "+actor+describeReason(reason)+".")
+
+	message := fmt.Sprintf("You have been banned for %d minutes.", int(duration.Minutes()))
+	if reason != "" {
+		message += " Reason: " + reason
+	}
+
+	chat.KickUser(target.ID, message)
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_BAN,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+		Reason: reason,
+	})
+}
+
+func Mute(actor string, target chat.ChatUser, reason string, duration time.Duration) {
+	chat.MuteUser(target, time.Now().UTC().Add(duration))
+
+	chat.SendSystemNotice(target, fmt.Sprintf(
+		"{nickname}, you have been muted for %d minutes%s.", int(duration.Minutes()), describeReason(reason),
+	))
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_MUTE,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+		Reason: reason,
+	})
+}
+
+func Unmute(actor string, target chat.ChatUser) {
+	chat.UnmuteUser(target)
+
+	chat.SendSystemNotice(target, "{nickname}, you can talk again.")
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_UNMUTE,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+	})
+}
+
+// Shadow keeps the user around, but only they and moderators see what
+// they say from now on, even if they come back with another nickname.
+func Shadow(actor string, target chat.ChatUser, reason string, duration time.Duration) {
+	issuedBy := html.UnescapeString(actor)
+
+	if target.IP != "" {
+		bans.AddShadow(bans.TYPE_IP, target.IP, reason, issuedBy, duration)
+	}
+
+	if target.Fingerprint != "" {
+		bans.AddShadow(bans.TYPE_FINGERPRINT, target.Fingerprint, reason, issuedBy, duration)
+	}
+
+	if target.IsDiscordUser() {
+		bans.AddShadow(bans.TYPE_DISCORD, target.DiscordId, reason, issuedBy, duration)
+	}
+
+	chat.SetUserShadowed(target.ID, true)
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_SHADOW,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+		Reason: reason,
+	})
+}
+
+func Unshadow(actor string, target chat.ChatUser) {
+	bans.LiftShadow(bans.Candidate{
+		IP:          target.IP,
+		Nickname:    html.UnescapeString(target.Nickname),
+		Fingerprint: target.Fingerprint,
+		DiscordId:   target.DiscordId,
+	})
+
+	chat.SetUserShadowed(target.ID, false)
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_UNSHADOW,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+	})
+}
+
+// Purge removes the user's messages from every thread and
+// returns how many were removed.
+func Purge(actor string, target chat.ChatUser, reason string) int {
+	purged := chat.PurgeUserMessages(target.ID)
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_PURGE,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+		Reason: reason,
+	})
+
+	return len(purged)
+}
+
+// SetSlowMode lets users in the room talk once every given seconds,
+// 0 turns it off.
+func SetSlowMode(actor string, roomId string, seconds int) bool {
+	room, found := chat.SetSlowMode(roomId, seconds)
+	if !found {
+		return false
+	}
+
+	message := "{nickname} turned off slow mode."
+	reason := "slow mode off"
+	if seconds > 0 {
+		message = fmt.Sprintf("{nickname} turned on slow mode, everyone can send one message every %d seconds.", seconds)
+		reason = fmt.Sprintf("slow mode %ds", seconds)
+	}
+
+	// The actor might not be in the room, they're only shown by name
+	announce(chat.ChatUser{Nickname: actor, Color: chat.USER_COLOR_BLACK, RoomId: room.ID}, message)
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_ROOM_CHANGE,
+		RoomID: room.ID,
+		Actor:  actor,
+		Reason: reason,
+	})
+
+	return true
+}
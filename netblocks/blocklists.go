@@ -0,0 +1,182 @@
+package netblocks
+
+import (
+	"bufio"
+	"log"
+	"net/netip"
+	"os"
+	"retro-chat-rooms/config"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	mutex      sync.Mutex
+	blocklists []*Blocklist
+	loadOnce   sync.Once
+)
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	bytes := prefix.Masked().Addr().AsSlice()
+	bits := prefix.Bits()
+
+	for i := range bytes {
+		// Bits past the prefix are all set in the last address
+		hostBits := max(0, min(8, (i+1)*8-bits))
+		bytes[i] |= byte(1<<hostBits - 1)
+	}
+
+	last, _ := netip.AddrFromSlice(bytes)
+	return last
+}
+
+// parseLine reads an IP or CIDR range, comments start with #.
+func parseLine(line string) (addrRange, bool) {
+	line, _, _ = strings.Cut(line, "#")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return addrRange{}, false
+	}
+
+	if !strings.Contains(line, "/") {
+		addr, err := netip.ParseAddr(line)
+		if err != nil {
+			return addrRange{}, false
+		}
+		addr = addr.Unmap()
+		return addrRange{First: addr, Last: addr}, true
+	}
+
+	prefix, err := netip.ParsePrefix(line)
+	if err != nil {
+		return addrRange{}, false
+	}
+	if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+
+	prefix = prefix.Masked()
+	return addrRange{First: prefix.Addr(), Last: lastAddr(prefix)}, true
+}
+
+// load reads the file again, expects mutex to be locked.
+func (b *Blocklist) load() {
+	b.loadedAt = modTime(b.File)
+	b.ranges = []addrRange{}
+
+	file, err := os.Open(b.File)
+	if err != nil {
+		log.Printf("Couldn't read the IP blocklist %s: %v", b.File, err)
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	line := 0
+	for scanner.Scan() {
+		line++
+		r, ok := parseLine(scanner.Text())
+		if !ok {
+			if strings.TrimSpace(strings.SplitN(scanner.Text(), "#", 2)[0]) != "" {
+				log.Printf("Skipping %s:%d, not an IP or CIDR range", b.File, line)
+			}
+			continue
+		}
+		b.ranges = append(b.ranges, r)
+	}
+
+	sort.Slice(b.ranges, func(i, j int) bool {
+		return b.ranges[i].First.Less(b.ranges[j].First)
+	})
+
+	// Overlapping ranges are joined so the search only has to look at one
+	merged := []addrRange{}
+	for _, r := range b.ranges {
+		last := len(merged) - 1
+		if last >= 0 && merged[last].Last.BitLen() == r.First.BitLen() && !merged[last].Last.Less(r.First) {
+			if merged[last].Last.Less(r.Last) {
+				merged[last].Last = r.Last
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	b.ranges = merged
+}
+
+func (b *Blocklist) contains(addr netip.Addr) bool {
+	// The first range starting after the address, the one before may have it
+	i := sort.Search(len(b.ranges), func(i int) bool {
+		return addr.Less(b.ranges[i].First)
+	})
+
+	return i > 0 && !b.ranges[i-1].Last.Less(addr) && !addr.Less(b.ranges[i-1].First)
+}
+
+// ensureLoaded reads the files from the config, expects mutex to be locked.
+func ensureLoaded() {
+	loadOnce.Do(func() {
+		for _, cfg := range config.Current.IPBlocklists {
+			b := &Blocklist{File: cfg.File, Reason: cfg.Reason}
+			b.load()
+			blocklists = append(blocklists, b)
+		}
+	})
+}
+
+// ReloadChanged reads the files edited since they were loaded again,
+// returns the ones that changed.
+func ReloadChanged() []string {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	changed := []string{}
+	for _, b := range blocklists {
+		if !modTime(b.File).Equal(b.loadedAt) {
+			b.load()
+			changed = append(changed, b.File)
+		}
+	}
+
+	return changed
+}
+
+// Check returns the blocklist the IP is on, if any.
+func Check(ip string) (Blocklist, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Blocklist{}, false
+	}
+	addr = addr.Unmap().WithZone("")
+
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	for _, b := range blocklists {
+		if b.contains(addr) {
+			return *b, true
+		}
+	}
+
+	return Blocklist{}, false
+}
+
+// Describe tells the blocked user why they can't get in.
+func (b Blocklist) Describe() string {
+	if b.Reason != "" {
+		return "Your network is blocked: " + b.Reason + "."
+	}
+	return "Your network is blocked from this chat."
+}
@@ -0,0 +1,11 @@
+package netblocks
+
+// IPv6 users get a whole block from their ISP and can hop around in it,
+// so they are grouped by prefix instead of by address.
+const (
+	DEFAULT_IPV6_PREFIX = 64
+	DEFAULT_IPV4_PREFIX = 32
+)
+
+// How often the blocklist files are checked for changes
+const RELOAD_CHECK_SEC = 30
@@ -0,0 +1,49 @@
+package netblocks
+
+import (
+	"net/netip"
+	"retro-chat-rooms/config"
+)
+
+func prefixLength(addr netip.Addr) int {
+	if addr.Is4() {
+		if bits := config.Current.IPGrouping.IPv4Prefix; bits > 0 && bits <= 32 {
+			return bits
+		}
+		return DEFAULT_IPV4_PREFIX
+	}
+
+	if bits := config.Current.IPGrouping.IPv6Prefix; bits > 0 && bits <= 128 {
+		return bits
+	}
+	return DEFAULT_IPV6_PREFIX
+}
+
+// GroupKey returns what flood control and bans track the IP as, the
+// address itself for IPv4 and its prefix for IPv6, ex: 2001:db8:1:2::/64.
+// Anything that isn't an IP is returned as it is.
+func GroupKey(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+
+	addr = addr.Unmap().WithZone("")
+	bits := prefixLength(addr)
+
+	if bits == addr.BitLen() {
+		return addr.String()
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return addr.String()
+	}
+	return prefix.String()
+}
+
+// SameGroup tells if both IPs belong to the same user as far as the
+// grouping goes.
+func SameGroup(a string, b string) bool {
+	return a != "" && b != "" && GroupKey(a) == GroupKey(b)
+}
@@ -0,0 +1,137 @@
+package netblocks
+
+import (
+	"os"
+	"path/filepath"
+	"retro-chat-rooms/config"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGroupKey(t *testing.T) {
+	cases := []struct {
+		name     string
+		grouping config.IPGroupingConfig
+		ip       string
+		want     string
+	}{
+		{"IPv4 alone", config.IPGroupingConfig{}, "192.0.2.7", "192.0.2.7"},
+		{"IPv4 mapped", config.IPGroupingConfig{}, "::ffff:192.0.2.7", "192.0.2.7"},
+		{"IPv6 by /64", config.IPGroupingConfig{}, "2001:db8:1:2:3:4:5:6", "2001:db8:1:2::/64"},
+		{"IPv6 zone dropped", config.IPGroupingConfig{}, "fe80::1%eth0", "fe80::/64"},
+		{"IPv6 by /48", config.IPGroupingConfig{IPv6Prefix: 48}, "2001:db8:1:2::1", "2001:db8:1::/48"},
+		{"IPv6 alone", config.IPGroupingConfig{IPv6Prefix: 128}, "2001:db8::1", "2001:db8::1"},
+		{"IPv4 by /24", config.IPGroupingConfig{IPv4Prefix: 24}, "192.0.2.7", "192.0.2.0/24"},
+		{"out of range keeps the default", config.IPGroupingConfig{IPv4Prefix: 40, IPv6Prefix: 200}, "2001:db8::1", "2001:db8::/64"},
+		{"not an IP", config.IPGroupingConfig{}, "discord:1234", "discord:1234"},
+	}
+
+	for _, c := range cases {
+		config.Current.IPGrouping = c.grouping
+		if got := GroupKey(c.ip); got != c.want {
+			t.Errorf("%s: GroupKey(%q) = %q, want %q", c.name, c.ip, got, c.want)
+		}
+	}
+
+	config.Current.IPGrouping = config.IPGroupingConfig{}
+
+	if SameGroup("", "") {
+		t.Error("two unknown IPs are the same user")
+	}
+	if !SameGroup("2001:db8::1", "2001:db8::2") {
+		t.Error("two addresses in a /64 aren't the same user")
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	cases := []struct {
+		line  string
+		ok    bool
+		first string
+		last  string
+	}{
+		{"192.0.2.1", true, "192.0.2.1", "192.0.2.1"},
+		{"  10.0.0.0/8  # a comment", true, "10.0.0.0", "10.255.255.255"},
+		{"10.1.2.3/16", true, "10.1.0.0", "10.1.255.255"},
+		{"198.51.100.0/23", true, "198.51.100.0", "198.51.101.255"},
+		{"::ffff:192.0.2.0/120", true, "192.0.2.0", "192.0.2.255"},
+		{"2001:db8::/32", true, "2001:db8::", "2001:db8:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"# only a comment", false, "", ""},
+		{"", false, "", ""},
+		{"not an ip", false, "", ""},
+		{"10.0.0.0/40", false, "", ""},
+	}
+
+	for _, c := range cases {
+		r, ok := parseLine(c.line)
+		if ok != c.ok {
+			t.Errorf("parseLine(%q) ok = %v, want %v", c.line, ok, c.ok)
+			continue
+		}
+		if ok && (r.First.String() != c.first || r.Last.String() != c.last) {
+			t.Errorf("parseLine(%q) = %s-%s, want %s-%s", c.line, r.First, r.Last, c.first, c.last)
+		}
+	}
+}
+
+func writeList(t *testing.T, file string, content string, modified time.Time) {
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Some filesystems only keep whole seconds
+	if err := os.Chtimes(file, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "proxies.txt")
+	writeList(t, file, "10.0.0.0/8\n10.5.0.0/16\n192.0.2.1\n2001:db8::/32\n", time.Now().Add(-time.Hour))
+
+	config.Current.IPBlocklists = []config.IPBlocklistConfig{{File: file, Reason: "known proxies"}}
+	blocklists, loadOnce = nil, sync.Once{}
+
+	cases := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"10.0.0.0", true},
+		{"10.255.255.255", true},
+		{"10.5.3.3", true},
+		{"11.0.0.0", false},
+		{"9.255.255.255", false},
+		{"192.0.2.1", true},
+		{"192.0.2.2", false},
+		{"::ffff:10.1.1.1", true},
+		{"2001:db8:abcd::1", true},
+		{"2001:db9::1", false},
+		{"not an ip", false},
+	}
+
+	for _, c := range cases {
+		if _, blocked := Check(c.ip); blocked != c.blocked {
+			t.Errorf("Check(%q) blocked = %v, want %v", c.ip, blocked, c.blocked)
+		}
+	}
+
+	if list, _ := Check("10.1.1.1"); list.Describe() != "Your network is blocked: known proxies." {
+		t.Errorf("unexpected description %q", list.Describe())
+	}
+
+	if changed := ReloadChanged(); len(changed) != 0 {
+		t.Errorf("reloaded %v without changes", changed)
+	}
+
+	writeList(t, file, "11.0.0.0/8\n", time.Now())
+
+	if changed := ReloadChanged(); len(changed) != 1 {
+		t.Fatalf("reloaded %v, want the edited list", changed)
+	}
+	if _, blocked := Check("10.1.1.1"); blocked {
+		t.Error("a range removed from the file is still blocked")
+	}
+	if _, blocked := Check("11.1.1.1"); !blocked {
+		t.Error("a range added to the file isn't blocked")
+	}
+}
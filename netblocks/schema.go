@@ -0,0 +1,23 @@
+package netblocks
+
+import (
+	"net/netip"
+	"time"
+)
+
+// Blocklist is a file of IPs and CIDR ranges kept out of the chat,
+// like known proxies and VPNs.
+type Blocklist struct {
+	File   string
+	Reason string
+
+	ranges   []addrRange
+	loadedAt time.Time
+}
+
+// addrRange is a CIDR range as its first and last addresses, so lists
+// with thousands of them can be searched quickly.
+type addrRange struct {
+	First netip.Addr
+	Last  netip.Addr
+}
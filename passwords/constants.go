@@ -0,0 +1,14 @@
+package passwords
+
+const (
+	// Legacy SHA-1 hashes wrapped in bcrypt by the migrate-passwords
+	// command, ex: sha1:$2a$10$...
+	WRAPPED_SHA1_PREFIX = "sha1:"
+	ARGON2ID_PREFIX     = "$argon2id$"
+
+	ARGON2_TIME        = 3
+	ARGON2_MEMORY_KIB  = 64 * 1024
+	ARGON2_THREADS     = 2
+	ARGON2_KEY_LENGTH  = 32
+	ARGON2_SALT_LENGTH = 16
+)
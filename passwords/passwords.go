@@ -0,0 +1,120 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var legacyExpr = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+func sha1Hex(password string) string {
+	hasher := sha1.New()
+	hasher.Write([]byte(password))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// IsLegacy tells if the hash is a plain unsalted SHA-1.
+func IsLegacy(hash string) bool {
+	return legacyExpr.MatchString(strings.TrimSpace(hash))
+}
+
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Hash creates a salted bcrypt hash to put in the config.
+func Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// HashArgon2 creates an argon2id hash in the usual
+// $argon2id$v=19$m=65536,t=3,p=2$salt$key format.
+func HashArgon2(password string) (string, error) {
+	salt := make([]byte, ARGON2_SALT_LENGTH)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, ARGON2_TIME, ARGON2_MEMORY_KIB, ARGON2_THREADS, ARGON2_KEY_LENGTH)
+
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		ARGON2ID_PREFIX, argon2.Version, ARGON2_MEMORY_KIB, ARGON2_TIME, ARGON2_THREADS,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// WrapLegacy turns a SHA-1 hash into a salted one without knowing
+// the password, Verify still accepts the same password.
+func WrapLegacy(hash string) (string, error) {
+	wrapped, err := bcrypt.GenerateFromPassword([]byte(strings.ToLower(strings.TrimSpace(hash))), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return WRAPPED_SHA1_PREFIX + string(wrapped), nil
+}
+
+func verifyArgon2(hash string, password string) bool {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false
+	}
+
+	var version int
+	var memory, time uint32
+	var threads uint8
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, computed) == 1
+}
+
+// Verify checks the password against any of the supported hashes:
+// bcrypt, argon2id, wrapped SHA-1 and legacy SHA-1.
+func Verify(hash string, password string) bool {
+	hash = strings.TrimSpace(hash)
+
+	switch {
+	case hash == "":
+		return false
+	case isBcrypt(hash):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, ARGON2ID_PREFIX):
+		return verifyArgon2(hash, password)
+	case strings.HasPrefix(hash, WRAPPED_SHA1_PREFIX):
+		wrapped := strings.TrimPrefix(hash, WRAPPED_SHA1_PREFIX)
+		return bcrypt.CompareHashAndPassword([]byte(wrapped), []byte(sha1Hex(password))) == nil
+	case IsLegacy(hash):
+		return subtle.ConstantTimeCompare([]byte(strings.ToLower(hash)), []byte(sha1Hex(password))) == 1
+	}
+
+	return false
+}
@@ -0,0 +1,79 @@
+package passwords
+
+import (
+	"strings"
+	"testing"
+)
+
+// SHA-1 of "secret"
+const legacySecret = "e5e9fa1ba31ecd1ae84f75caaa474f3a663f05f4"
+
+func mustHash(t *testing.T, hash func(string) (string, error), input string) string {
+	h, err := hash(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestVerify(t *testing.T) {
+	bcryptSecret := mustHash(t, Hash, "secret")
+	argonSecret := mustHash(t, HashArgon2, "secret")
+	wrappedSecret := mustHash(t, WrapLegacy, legacySecret)
+
+	cases := []struct {
+		name     string
+		hash     string
+		password string
+		want     bool
+	}{
+		{"bcrypt", bcryptSecret, "secret", true},
+		{"bcrypt wrong password", bcryptSecret, "Secret", false},
+		{"argon2id", argonSecret, "secret", true},
+		{"argon2id wrong password", argonSecret, "secret ", false},
+		{"wrapped sha1", wrappedSecret, "secret", true},
+		{"wrapped sha1 wrong password", wrappedSecret, "nope", false},
+		{"wrapped sha1 is not the sha1 itself", wrappedSecret, legacySecret, false},
+		{"legacy sha1", legacySecret, "secret", true},
+		{"legacy sha1 upper case", strings.ToUpper(legacySecret), "secret", true},
+		{"legacy sha1 wrong password", legacySecret, "nope", false},
+		{"empty hash", "", "", false},
+		{"plain text isn't a hash", "secret", "secret", false},
+		{"argon2id broken", "$argon2id$v=19$m=65536,t=3,p=2$abc", "secret", false},
+		{"argon2id other version", strings.Replace(argonSecret, "v=19", "v=16", 1), "secret", false},
+	}
+
+	for _, c := range cases {
+		if got := Verify(c.hash, c.password); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestHashesAreSalted(t *testing.T) {
+	for name, hash := range map[string]func(string) (string, error){"bcrypt": Hash, "argon2id": HashArgon2} {
+		if mustHash(t, hash, "secret") == mustHash(t, hash, "secret") {
+			t.Errorf("%s: the same password hashed the same twice", name)
+		}
+	}
+}
+
+func TestIsLegacy(t *testing.T) {
+	cases := []struct {
+		hash string
+		want bool
+	}{
+		{legacySecret, true},
+		{" " + legacySecret + "\n", true},
+		{legacySecret[:39], false},
+		{"sha1:" + legacySecret, false},
+		{"$2a$10$abcdefghijklmnopqrstuv", false},
+		{strings.Repeat("g", 40), false},
+	}
+
+	for _, c := range cases {
+		if got := IsLegacy(c.hash); got != c.want {
+			t.Errorf("IsLegacy(%q) = %v, want %v", c.hash, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package policies
+
+// What happens to words from a profanity list
+const (
+	WORDS_ALLOW  = "allow"
+	WORDS_CENSOR = "censor"
+	WORDS_BLOCK  = "block"
+)
+
+var WORD_ACTIONS = []string{WORDS_ALLOW, WORDS_CENSOR, WORDS_BLOCK}
+
+const (
+	LINKS_ALLOW = "allow"
+	LINKS_BLOCK = "block"
+	// Only voiced users and staff can post links
+	LINKS_TRUSTED = "trusted"
+)
+
+var LINK_RULES = []string{LINKS_ALLOW, LINKS_BLOCK, LINKS_TRUSTED}
+
+const (
+	// No words from either list in nicknames
+	NICKNAMES_STRICT = "strict"
+	// Only blocked words are kept out of nicknames
+	NICKNAMES_RELAXED = "relaxed"
+)
+
+var NICKNAME_RULES = []string{NICKNAMES_STRICT, NICKNAMES_RELAXED}
@@ -0,0 +1,110 @@
+package policies
+
+import (
+	"log"
+	"regexp"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/profanity"
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+var (
+	once     sync.Once
+	policies = map[string]Policy{}
+
+	linkExpr = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+`)
+)
+
+// The family friendly rules every room had before policies
+var Default = Policy{
+	CensoredWords: WORDS_CENSOR,
+	BlockedWords:  WORDS_BLOCK,
+	Screaming:     true,
+	Links:         LINKS_ALLOW,
+	Nicknames:     NICKNAMES_STRICT,
+}
+
+func pick(value string, allowed []string, fallback string, what string, name string) string {
+	if value == "" {
+		return fallback
+	}
+	if !lo.Contains(allowed, value) {
+		log.Printf("Policy %s has an unknown %s %q, using %q", name, what, value, fallback)
+		return fallback
+	}
+	return value
+}
+
+func load() {
+	for name, cfg := range config.Current.Policies {
+		policies[name] = Policy{
+			Name:          name,
+			CensoredWords: pick(cfg.CensoredWords, WORD_ACTIONS, Default.CensoredWords, "censored-words", name),
+			BlockedWords:  pick(cfg.BlockedWords, WORD_ACTIONS, Default.BlockedWords, "blocked-words", name),
+			Screaming:     !cfg.NoScreaming,
+			Links:         pick(cfg.Links, LINK_RULES, Default.Links, "links", name),
+			Nicknames:     pick(cfg.Nicknames, NICKNAME_RULES, Default.Nicknames, "nicknames", name),
+		}
+	}
+
+	for _, room := range config.Current.Rooms {
+		if _, found := policies[room.Policy]; room.Policy != "" && !found {
+			log.Printf("Room %s uses the policy %q that doesn't exist, using the defaults", room.ID, room.Policy)
+		}
+	}
+}
+
+// ForRoom returns the policy of the room, or the defaults.
+func ForRoom(roomId string) Policy {
+	once.Do(load)
+
+	for _, room := range config.Current.Rooms {
+		if room.ID != roomId {
+			continue
+		}
+		if policy, found := policies[room.Policy]; found {
+			return policy
+		}
+	}
+
+	return Default
+}
+
+// IsBlocked tells if the message can't be sent at all because of the
+// words in it.
+func (p Policy) IsBlocked(message string) bool {
+	return (p.BlockedWords == WORDS_BLOCK && profanity.Has(profanity.LIST_BLOCKED, message)) ||
+		(p.CensoredWords == WORDS_BLOCK && profanity.Has(profanity.LIST_CENSORED, message))
+}
+
+// Censor stars out the words the policy censors.
+func (p Policy) Censor(message string) string {
+	if p.CensoredWords == WORDS_CENSOR {
+		message = profanity.Censor(profanity.LIST_CENSORED, message)
+	}
+	if p.BlockedWords == WORDS_CENSOR {
+		message = profanity.Censor(profanity.LIST_BLOCKED, message)
+	}
+	return message
+}
+
+// AllowsLinks tells if the message can go out with the links in it,
+// trusted is for voiced users and staff.
+func (p Policy) AllowsLinks(message string, trusted bool) bool {
+	switch p.Links {
+	case LINKS_BLOCK:
+		return !linkExpr.MatchString(message)
+	case LINKS_TRUSTED:
+		return trusted || !linkExpr.MatchString(message)
+	}
+	return true
+}
+
+func (p Policy) AllowsNickname(nickname string) bool {
+	if p.Nicknames == NICKNAMES_RELAXED {
+		return !profanity.Has(profanity.LIST_BLOCKED, nickname)
+	}
+	return !profanity.IsProfaneNickname(nickname)
+}
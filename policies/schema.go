@@ -0,0 +1,12 @@
+package policies
+
+// Policy is what a room allows people to say, rooms without one get
+// the defaults.
+type Policy struct {
+	Name          string
+	CensoredWords string
+	BlockedWords  string
+	Screaming     bool
+	Links         string
+	Nicknames     string
+}
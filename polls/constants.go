@@ -0,0 +1,10 @@
+package polls
+
+const (
+	// used when the config doesn't say otherwise
+	DEFAULT_MAX_OPTIONS      = 6
+	DEFAULT_MAX_DURATION_MIN = 60
+
+	MIN_OPTIONS      = 2
+	MIN_DURATION_MIN = 1
+)
@@ -0,0 +1,283 @@
+package polls
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/roles"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+var (
+	// Open polls, one per room at most
+	polls = map[string]*Poll{}
+
+	mutex = sync.Mutex{}
+)
+
+var (
+	ErrNotAllowed     = errors.New("you are not allowed to manage polls")
+	ErrPollRunning    = errors.New("there is already a poll running in this room")
+	ErrNoPoll         = errors.New("there is no poll running in this room")
+	ErrInvalidOption  = errors.New("that option doesn't exist")
+	ErrAlreadyVoted   = errors.New("you already voted on this poll")
+	ErrTooFewOptions  = fmt.Errorf("a poll needs at least %d options", MIN_OPTIONS)
+	ErrEmptyQuestion  = errors.New("a poll needs a question")
+	ErrInvalidTimeout = errors.New("invalid poll duration")
+)
+
+func maxOptions() int {
+	if config.Current.Polls.MaxOptions > 0 {
+		return config.Current.Polls.MaxOptions
+	}
+	return DEFAULT_MAX_OPTIONS
+}
+
+func maxDurationMin() int {
+	if config.Current.Polls.MaxDurationMin > 0 {
+		return config.Current.Polls.MaxDurationMin
+	}
+	return DEFAULT_MAX_DURATION_MIN
+}
+
+// CanOpen tells if the user is allowed to start a poll.
+func CanOpen(user chat.ChatUser) bool {
+	return roles.Can(user, roles.PERM_POLLS) || config.Current.Polls.OpenToEveryone
+}
+
+// copyPoll returns a copy that is safe to hand out of the package.
+func copyPoll(p *Poll) Poll {
+	c := *p
+	c.Options = append([]PollOption{}, p.Options...)
+	c.voterIDs = nil
+	c.voterIPs = nil
+	return c
+}
+
+func GetActivePoll(roomId string) (Poll, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	p, found := polls[roomId]
+	if !found {
+		return Poll{}, false
+	}
+
+	return copyPoll(p), true
+}
+
+func OpenPoll(creator chat.ChatUser, question string, options []string, durationMin int) (Poll, error) {
+	if !CanOpen(creator) {
+		return Poll{}, ErrNotAllowed
+	}
+
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return Poll{}, ErrEmptyQuestion
+	}
+
+	options = lo.Filter(lo.Map(options, func(o string, _ int) string {
+		return strings.TrimSpace(o)
+	}), func(o string, _ int) bool {
+		return o != ""
+	})
+
+	if len(options) < MIN_OPTIONS {
+		return Poll{}, ErrTooFewOptions
+	}
+
+	if len(options) > maxOptions() {
+		return Poll{}, fmt.Errorf("a poll can have at most %d options", maxOptions())
+	}
+
+	if durationMin < MIN_DURATION_MIN || durationMin > maxDurationMin() {
+		return Poll{}, ErrInvalidTimeout
+	}
+
+	now := time.Now().UTC()
+
+	mutex.Lock()
+	if _, found := polls[creator.RoomId]; found {
+		mutex.Unlock()
+		return Poll{}, ErrPollRunning
+	}
+
+	poll := &Poll{
+		ID:       uuid.NewString(),
+		RoomID:   creator.RoomId,
+		Question: question,
+		Options: lo.Map(options, func(o string, _ int) PollOption {
+			return PollOption{Label: o}
+		}),
+		CreatedBy: creator,
+		CreatedAt: now,
+		EndsAt:    now.Add(time.Duration(durationMin) * time.Minute),
+		voterIDs:  map[string]bool{},
+		voterIPs:  map[string]bool{},
+	}
+	polls[creator.RoomId] = poll
+	result := copyPoll(poll)
+	mutex.Unlock()
+
+	announce(result, "{nickname} started a poll: ", FormatPoll(result, false)+
+		fmt.Sprintf(" Vote with /vote followed by the option number before %s.", result.EndsAt.Format("03:04 PM")))
+
+	return result, nil
+}
+
+// Vote records the user's choice, option is 1 based.
+func Vote(roomId string, user chat.ChatUser, ip string, option int) error {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	p, found := polls[roomId]
+	if !found || time.Now().UTC().After(p.EndsAt) {
+		return ErrNoPoll
+	}
+
+	if option < 1 || option > len(p.Options) {
+		return ErrInvalidOption
+	}
+
+	if p.voterIDs[user.ID] || (ip != "" && p.voterIPs[ip]) {
+		return ErrAlreadyVoted
+	}
+
+	p.voterIDs[user.ID] = true
+	if ip != "" {
+		p.voterIPs[ip] = true
+	}
+	p.Options[option-1].Votes++
+
+	return nil
+}
+
+// ClosePoll ends the room's poll before its deadline.
+func ClosePoll(roomId string, user chat.ChatUser) error {
+	mutex.Lock()
+	p, found := polls[roomId]
+	if !found {
+		mutex.Unlock()
+		return ErrNoPoll
+	}
+
+	if !roles.Can(user, roles.PERM_POLLS) && p.CreatedBy.ID != user.ID {
+		mutex.Unlock()
+		return ErrNotAllowed
+	}
+	mutex.Unlock()
+
+	closePoll(roomId)
+	return nil
+}
+
+func CloseExpiredPolls() {
+	now := time.Now().UTC()
+
+	mutex.Lock()
+	expired := lo.Filter(lo.Keys(polls), func(roomId string, _ int) bool {
+		return now.After(polls[roomId].EndsAt)
+	})
+	mutex.Unlock()
+
+	for _, roomId := range expired {
+		closePoll(roomId)
+	}
+}
+
+func closePoll(roomId string) {
+	mutex.Lock()
+	p, found := polls[roomId]
+	if !found {
+		mutex.Unlock()
+		return
+	}
+	delete(polls, roomId)
+	result := copyPoll(p)
+	mutex.Unlock()
+
+	announce(result, "The poll by {nickname} is closed! ", FormatPoll(result, true))
+
+	if events, found := chat.RoomEvents[roomId]; found {
+		events.Publish(PollClosedEvent{Poll: result})
+	}
+}
+
+// FormatPoll describes the poll as plain text, with or without the results.
+func FormatPoll(p Poll, withResults bool) string {
+	var b strings.Builder
+
+	b.WriteString(p.Question)
+
+	for i, o := range p.Options {
+		b.WriteString(fmt.Sprintf(" %d) %s", i+1, o.Label))
+		if withResults {
+			b.WriteString(fmt.Sprintf(": %d %s", o.Votes, pluralize(o.Votes, "vote", "votes")))
+		}
+		if i < len(p.Options)-1 {
+			b.WriteString(",")
+		}
+	}
+
+	if withResults {
+		b.WriteString(". " + describeWinner(p))
+	} else {
+		b.WriteString(".")
+	}
+
+	return b.String()
+}
+
+func describeWinner(p Poll) string {
+	best := lo.MaxBy(p.Options, func(a PollOption, b PollOption) bool {
+		return a.Votes > b.Votes
+	})
+
+	if best.Votes == 0 {
+		return "Nobody voted."
+	}
+
+	winners := lo.Filter(p.Options, func(o PollOption, _ int) bool {
+		return o.Votes == best.Votes
+	})
+
+	if len(winners) > 1 {
+		return "It's a tie!"
+	}
+
+	return "Winner: " + best.Label
+}
+
+func pluralize(n int, singular string, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+func announce(p Poll, prefix string, text string) {
+	creator := p.CreatedBy
+
+	chat.SendMessage(&chat.ChatMessage{
+		RoomID: p.RoomID,
+		Time:   time.Now().UTC(),
+		// The question and options come from a user, so they go through
+		// the same escaping as nicknames do.
+		Message:              prefix + template.HTMLEscapeString(text),
+		IsSystemMessage:      true,
+		SystemMessageSubject: &creator,
+		Privately:            false,
+		SpeechMode:           chat.MODE_SAY_TO,
+		From:                 creator.ID,
+		To:                   "",
+		ShowClientIcon:       false,
+		InvolvedUsers:        []chat.ChatUser{creator},
+	})
+}
@@ -0,0 +1,171 @@
+package polls
+
+import (
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/pubsub"
+	"testing"
+	"time"
+)
+
+var (
+	voiced  = chat.ChatUser{ID: "general-voiced", Nickname: "Voiced", RoomId: "general", Role: chat.ROLE_VOICED}
+	regular = chat.ChatUser{ID: "general-regular", Nickname: "Regular", RoomId: "general"}
+	op      = chat.ChatUser{ID: "general-op", Nickname: "Op", RoomId: "general", Role: chat.ROLE_ROOM_OPERATOR}
+)
+
+func reset() {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	polls = map[string]*Poll{}
+	config.Current.Polls = config.PollsConfig{}
+	chat.RoomEvents["general"] = pubsub.NewPubsub()
+}
+
+func TestOpenPoll(t *testing.T) {
+	cases := []struct {
+		name     string
+		creator  chat.ChatUser
+		everyone bool
+		question string
+		options  []string
+		duration int
+		err      error
+	}{
+		{"voiced", voiced, false, "Pizza?", []string{"yes", "no"}, 5, nil},
+		{"no role", regular, false, "Pizza?", []string{"yes", "no"}, 5, ErrNotAllowed},
+		{"open to everyone", regular, true, "Pizza?", []string{"yes", "no"}, 5, nil},
+		{"blank question", voiced, false, "  ", []string{"yes", "no"}, 5, ErrEmptyQuestion},
+		{"blank options don't count", voiced, false, "Pizza?", []string{"yes", " ", ""}, 5, ErrTooFewOptions},
+		{"too short", voiced, false, "Pizza?", []string{"yes", "no"}, 0, ErrInvalidTimeout},
+		{"too long", voiced, false, "Pizza?", []string{"yes", "no"}, DEFAULT_MAX_DURATION_MIN + 1, ErrInvalidTimeout},
+	}
+
+	for _, c := range cases {
+		reset()
+		config.Current.Polls.OpenToEveryone = c.everyone
+
+		if _, err := OpenPoll(c.creator, c.question, c.options, c.duration); err != c.err {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.err)
+		}
+	}
+
+	reset()
+	if _, err := OpenPoll(voiced, "Pizza?", []string{"1", "2", "3", "4", "5", "6", "7"}, 5); err == nil {
+		t.Error("opened a poll with too many options")
+	}
+
+	poll, err := OpenPoll(voiced, " Pizza? ", []string{" yes ", "no"}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poll.Question != "Pizza?" || poll.Options[0].Label != "yes" {
+		t.Errorf("question and options weren't trimmed: %+v", poll)
+	}
+	if _, err := OpenPoll(op, "Tacos?", []string{"yes", "no"}, 5); err != ErrPollRunning {
+		t.Errorf("second poll in the room: got %v, want %v", err, ErrPollRunning)
+	}
+}
+
+func TestVote(t *testing.T) {
+	reset()
+	if _, err := OpenPoll(voiced, "Pizza?", []string{"yes", "no"}, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	votes := []struct {
+		name   string
+		userId string
+		ip     string
+		option int
+		err    error
+	}{
+		{"first vote", "general-a", "192.0.2.1", 1, nil},
+		{"same user", "general-a", "192.0.2.9", 2, ErrAlreadyVoted},
+		{"same IP", "general-b", "192.0.2.1", 2, ErrAlreadyVoted},
+		{"no such option", "general-c", "192.0.2.3", 3, ErrInvalidOption},
+		{"option zero", "general-c", "192.0.2.3", 0, ErrInvalidOption},
+		{"another user", "general-c", "192.0.2.3", 2, nil},
+		{"no IP to compare", "general-d", "", 2, nil},
+	}
+
+	for _, v := range votes {
+		if err := Vote("general", chat.ChatUser{ID: v.userId}, v.ip, v.option); err != v.err {
+			t.Errorf("%s: got %v, want %v", v.name, err, v.err)
+		}
+	}
+
+	poll, _ := GetActivePoll("general")
+	if poll.Options[0].Votes != 1 || poll.Options[1].Votes != 2 {
+		t.Errorf("counted %+v, want 1 and 2 votes", poll.Options)
+	}
+
+	if err := Vote("random", regular, "", 1); err != ErrNoPoll {
+		t.Errorf("vote in a room without a poll: got %v, want %v", err, ErrNoPoll)
+	}
+
+	mutex.Lock()
+	polls["general"].EndsAt = time.Now().Add(-time.Second)
+	mutex.Unlock()
+
+	if err := Vote("general", chat.ChatUser{ID: "general-e"}, "", 1); err != ErrNoPoll {
+		t.Errorf("vote after the deadline: got %v, want %v", err, ErrNoPoll)
+	}
+
+	CloseExpiredPolls()
+	if _, found := GetActivePoll("general"); found {
+		t.Error("the expired poll is still open")
+	}
+}
+
+func TestClosePoll(t *testing.T) {
+	cases := []struct {
+		name string
+		user chat.ChatUser
+		err  error
+	}{
+		{"creator", regular, nil},
+		{"operator", op, nil},
+		{"someone else", chat.ChatUser{ID: "general-other", RoomId: "general"}, ErrNotAllowed},
+	}
+
+	for _, c := range cases {
+		reset()
+		config.Current.Polls.OpenToEveryone = true
+		if _, err := OpenPoll(regular, "Pizza?", []string{"yes", "no"}, 5); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ClosePoll("general", c.user); err != c.err {
+			t.Errorf("%s: got %v, want %v", c.name, err, c.err)
+		}
+		if _, found := GetActivePoll("general"); found != (c.err != nil) {
+			t.Errorf("%s: poll open is %v", c.name, found)
+		}
+	}
+}
+
+func TestFormatPoll(t *testing.T) {
+	cases := []struct {
+		name  string
+		votes []int
+		want  string
+	}{
+		{"winner", []int{1, 3}, "Pizza? 1) yes: 1 vote, 2) no: 3 votes. Winner: no"},
+		{"tie", []int{2, 2}, "Pizza? 1) yes: 2 votes, 2) no: 2 votes. It's a tie!"},
+		{"no votes", []int{0, 0}, "Pizza? 1) yes: 0 votes, 2) no: 0 votes. Nobody voted."},
+	}
+
+	for _, c := range cases {
+		poll := Poll{Question: "Pizza?", Options: []PollOption{{"yes", c.votes[0]}, {"no", c.votes[1]}}}
+		if got := FormatPoll(poll, true); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+
+	poll := Poll{Question: "Pizza?", Options: []PollOption{{"yes", 1}, {"no", 0}}}
+	if got := FormatPoll(poll, false); got != "Pizza? 1) yes, 2) no." {
+		t.Errorf("without results: got %q", got)
+	}
+}
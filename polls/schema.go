@@ -0,0 +1,29 @@
+package polls
+
+import (
+	"retro-chat-rooms/chat"
+	"time"
+)
+
+type PollOption struct {
+	Label string
+	Votes int
+}
+
+type Poll struct {
+	ID        string
+	RoomID    string
+	Question  string
+	Options   []PollOption
+	CreatedBy chat.ChatUser
+	CreatedAt time.Time
+	EndsAt    time.Time
+	// Who voted already, so nobody votes twice
+	voterIDs map[string]bool
+	voterIPs map[string]bool
+}
+
+// Published in the room events when a poll closes
+type PollClosedEvent struct {
+	Poll Poll
+}
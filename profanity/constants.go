@@ -0,0 +1,30 @@
+package profanity
+
+const (
+	LIST_CENSORED = "censored"
+	LIST_BLOCKED  = "blocked"
+)
+
+var LISTS = []string{LIST_CENSORED, LIST_BLOCKED}
+
+var files = map[string]string{
+	LIST_CENSORED: "censored-profanity.txt",
+	LIST_BLOCKED:  "fully-blocked-profanity.txt",
+}
+
+const DEFAULT_DIRECTORY = "profanity"
+
+// How entries match, written as a prefix in the files, ex: regex:f+u+
+const (
+	// Not part of a longer word, the default
+	MODE_WORD      = "word"
+	MODE_SUBSTRING = "substring"
+	MODE_REGEX     = "regex"
+)
+
+var MODES = []string{MODE_WORD, MODE_SUBSTRING, MODE_REGEX}
+
+const MAX_ENTRY_LENGTH = 100
+
+// How often the files are checked for changes
+const RELOAD_CHECK_SEC = 5
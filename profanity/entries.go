@@ -0,0 +1,86 @@
+package profanity
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/samber/lo"
+)
+
+var (
+	ErrEmptyEntry    = errors.New("the word is empty")
+	ErrEntryTooLong  = errors.New("the word is too long")
+	ErrUnknownMode   = errors.New("it has to be word, substring or regex")
+	ErrMatchesAll    = errors.New("the pattern matches everything")
+	ErrNoLetters     = errors.New("the word has no letters")
+	ErrDuplicate     = errors.New("it is on the list already")
+	ErrEntryNotFound = errors.New("it isn't on the list")
+	ErrUnknownList   = errors.New("the list has to be censored or blocked")
+)
+
+// ParseEntry reads a line from the files, plain words match whole words
+// and a mode prefix changes that, ex: "substring:crap".
+func ParseEntry(line string) (Entry, error) {
+	line = strings.TrimSpace(line)
+	entry := Entry{Text: line, Mode: MODE_WORD}
+
+	if mode, text, found := strings.Cut(line, ":"); found && lo.Contains(MODES, mode) {
+		entry = Entry{Text: strings.TrimSpace(text), Mode: mode}
+	}
+
+	return entry, entry.compile()
+}
+
+// NewEntry checks an entry typed by a moderator.
+func NewEntry(mode string, text string) (Entry, error) {
+	if mode == "" {
+		mode = MODE_WORD
+	}
+	if !lo.Contains(MODES, mode) {
+		return Entry{}, ErrUnknownMode
+	}
+
+	entry := Entry{Text: strings.TrimSpace(text), Mode: mode}
+	return entry, entry.compile()
+}
+
+func (e *Entry) compile() error {
+	if e.Text == "" {
+		return ErrEmptyEntry
+	}
+	if utf8.RuneCountInString(e.Text) > MAX_ENTRY_LENGTH {
+		return ErrEntryTooLong
+	}
+
+	// Words are matched by the automaton, only regexes need compiling
+	if e.Mode != MODE_REGEX {
+		if !slices.ContainsFunc(normalizeWord(e.Text), unicode.IsLetter) {
+			return ErrNoLetters
+		}
+		return nil
+	}
+
+	expr, err := regexp.Compile("(?i)" + e.Text)
+	if err != nil {
+		return errors.New("the pattern is not valid")
+	}
+
+	if expr.MatchString("") {
+		return ErrMatchesAll
+	}
+
+	e.expr = expr
+	return nil
+}
+
+// String is how the entry is written in the files.
+func (e Entry) String() string {
+	if e.Mode == MODE_WORD {
+		return e.Text
+	}
+	return e.Mode + ":" + e.Text
+}
@@ -2,13 +2,45 @@ package profanity
 
 import (
 	"bufio"
+	"log"
 	"os"
-	"regexp"
+	"path/filepath"
+	"retro-chat-rooms/config"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/samber/lo"
 )
 
+var (
+	mutex sync.Mutex
+	lists = map[string][]Entry{}
+	// Built again whenever a list changes
+	matchers = map[string]*matcher{}
+	// When each file was last changed, to notice edits
+	loadedAt = map[string]time.Time{}
+)
+
+func directory() string {
+	if config.Current.Profanity.Directory != "" {
+		return config.Current.Profanity.Directory
+	}
+	return DEFAULT_DIRECTORY
+}
+
+func filePath(list string) string {
+	return filepath.Join(directory(), files[list])
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
 // readLines reads a whole file into memory
 // and returns a slice of its lines.
 func readLines(path string) ([]string, error) {
@@ -26,78 +58,186 @@ func readLines(path string) ([]string, error) {
 	return lines, scanner.Err()
 }
 
-var censored []string
-var blocked []string
+func writeLines(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
 
-func LoadProfanityFilters() {
-	var err error
-	censored, err = readLines("profanity/censored-profanity.txt")
-	if err != nil {
-		panic(err)
+	// Written next to it and swapped in, so a reload never sees half a file
+	temp := path + ".tmp"
+	if err := os.WriteFile(temp, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return err
 	}
+	return os.Rename(temp, path)
+}
+
+func loadList(list string) {
+	path := filePath(list)
+	loadedAt[list] = modTime(path)
 
-	blocked, err = readLines("profanity/fully-blocked-profanity.txt")
+	lines, err := readLines(path)
 	if err != nil {
-		panic(err)
+		log.Printf("Couldn't read the %s words from %s: %v", list, path, err)
+		lists[list] = []Entry{}
+		matchers[list] = newMatcher(nil)
+		return
 	}
-}
 
-func ReplaceSensoredProfanity(input string) string {
-	words := strings.Split(input, " ")
-	words = lo.Map(words, func(word string, _ int) string {
-		if word == "" {
-			return word
+	entries := make([]Entry, 0, len(lines))
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
 		}
-		exists := lo.Contains(censored, strings.ToLower(word))
-		if !exists {
-			return word
+
+		entry, err := ParseEntry(line)
+		if err != nil {
+			log.Printf("Skipping %s:%d: %v", path, i+1, err)
+			continue
 		}
+		entries = append(entries, entry)
+	}
 
-		chars := strings.Split(word, "")
-		chars = lo.Map(chars, func(c string, i int) string {
-			if i == 0 || i >= (len(chars)-1) {
-				return c
-			}
+	lists[list] = entries
+	matchers[list] = newMatcher(entries)
+}
 
-			return "*"
-		})
+func LoadProfanityFilters() {
+	defer mutex.Unlock()
+	mutex.Lock()
 
-		return strings.Join(chars, "")
-	})
+	for _, list := range LISTS {
+		loadList(list)
+	}
+}
 
-	return strings.Join(words, " ")
+// ReloadChanged reads the files edited since they were loaded again,
+// returns the lists that changed.
+func ReloadChanged() []string {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	changed := []string{}
+	for _, list := range LISTS {
+		if !modTime(filePath(list)).Equal(loadedAt[list]) {
+			loadList(list)
+			changed = append(changed, list)
+		}
+	}
+
+	return changed
 }
 
-func HasBlockedWords(input string) bool {
+func List(list string) []Entry {
+	defer mutex.Unlock()
+	mutex.Lock()
+	return append([]Entry{}, lists[list]...)
+}
 
-	for _, b := range blocked {
-		blockedRegex := regexp.MustCompile(`(?i)(^|[^a-zA-Z])` + b + `($|[^a-zA-Z])`)
+// Add puts the entry on the list and saves it to the list's file.
+func Add(list string, entry Entry) error {
+	defer mutex.Unlock()
+	mutex.Lock()
 
-		if blockedRegex.MatchString(input) {
-			return true
-		}
+	if _, found := files[list]; !found {
+		return ErrUnknownList
+	}
+
+	if lo.ContainsBy(lists[list], func(e Entry) bool { return strings.EqualFold(e.String(), entry.String()) }) {
+		return ErrDuplicate
 	}
 
-	return false
+	lines, _ := readLines(filePath(list))
+	if err := writeLines(filePath(list), append(lines, entry.String())); err != nil {
+		return err
+	}
+
+	loadList(list)
+	return nil
 }
 
-func IsProfaneNickname(input string) bool {
-	lowered := strings.ToLower(input)
+// Remove takes the entry, as written in the file, off the list.
+func Remove(list string, text string) error {
+	defer mutex.Unlock()
+	mutex.Lock()
 
-	if HasBlockedWords(lowered) {
-		return true
+	if _, found := files[list]; !found {
+		return ErrUnknownList
 	}
 
-	words := strings.Split(input, " ")
+	lines, err := readLines(filePath(list))
+	if err != nil {
+		return err
+	}
 
-	for _, word := range words {
-		if word == "" {
-			continue
-		}
-		if lo.Contains(censored, strings.ToLower(word)) {
-			return true
+	kept := lo.Filter(lines, func(line string, _ int) bool {
+		entry, err := ParseEntry(line)
+		return err != nil || !strings.EqualFold(entry.String(), strings.TrimSpace(text))
+	})
+
+	if len(kept) == len(lines) {
+		return ErrEntryNotFound
+	}
+
+	if err := writeLines(filePath(list), kept); err != nil {
+		return err
+	}
+
+	loadList(list)
+	return nil
+}
+
+func censor(word string) string {
+	chars := strings.Split(word, "")
+	chars = lo.Map(chars, func(c string, i int) string {
+		if i == 0 || i >= (len(chars)-1) {
+			return c
 		}
+
+		return "*"
+	})
+
+	return strings.Join(chars, "")
+}
+
+// Censor stars out the words from the list.
+func Censor(list string, input string) string {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	found := mergeRanges(matchers[list].find(input, normalize(input), false))
+
+	// Backwards so the positions stay right
+	for i := len(found) - 1; i >= 0; i-- {
+		start, end := found[i][0], found[i][1]
+		input = input[:start] + censor(input[start:end]) + input[end:]
 	}
 
-	return false
+	return input
+}
+
+func hasAny(list string, input string, text normalized) bool {
+	return len(matchers[list].find(input, text, true)) > 0
+}
+
+// Has tells if the input has any word from the list.
+func Has(list string, input string) bool {
+	defer mutex.Unlock()
+	mutex.Lock()
+	return hasAny(list, input, normalize(input))
+}
+
+func ReplaceSensoredProfanity(input string) string {
+	return Censor(LIST_CENSORED, input)
+}
+
+func HasBlockedWords(input string) bool {
+	return Has(LIST_BLOCKED, input)
+}
+
+func IsProfaneNickname(input string) bool {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	text := normalize(input)
+	return hasAny(LIST_BLOCKED, input, text) || hasAny(LIST_CENSORED, input, text)
 }
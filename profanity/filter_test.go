@@ -0,0 +1,327 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"retro-chat-rooms/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// loadTestLists writes the lists to a temporary directory and loads them.
+func loadTestLists(t *testing.T, censored []string, blocked []string) {
+	t.Helper()
+
+	directory := t.TempDir()
+	config.Current.Profanity.Directory = directory
+
+	for list, words := range map[string][]string{LIST_CENSORED: censored, LIST_BLOCKED: blocked} {
+		if err := os.WriteFile(filepath.Join(directory, files[list]), []byte(strings.Join(words, "\n")), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	LoadProfanityFilters()
+}
+
+var testCensored = []string{"fuck", "shit", "ass", "cunt", "substring:crap", "regex:n+o+b+"}
+
+func TestHas(t *testing.T) {
+	loadTestLists(t, testCensored, []string{"nazi"})
+
+	tests := []struct {
+		name  string
+		list  string
+		input string
+		want  bool
+	}{
+		{"plain word", LIST_CENSORED, "oh fuck", true},
+		{"uppercase", LIST_CENSORED, "OH FUCK", true},
+		{"separators", LIST_CENSORED, "f.u.c.k this", true},
+		{"mixed separators", LIST_CENSORED, "f_u-c*k this", true},
+		{"spaced letters", LIST_CENSORED, "f u c k this", true},
+		{"leetspeak", LIST_CENSORED, "sh1t happens", true},
+		{"leetspeak symbols", LIST_CENSORED, "$hit happens", true},
+		{"cyrillic homoglyph", LIST_CENSORED, "what the fuсk", true},
+		{"fullwidth", LIST_CENSORED, "ｆｕｃｋ", true},
+		{"accents", LIST_CENSORED, "shït", true},
+		{"clean text", LIST_CENSORED, "hello everyone", false},
+		{"word inside pass", LIST_CENSORED, "pass the salt", false},
+		{"word inside class", LIST_CENSORED, "see you in class", false},
+		{"word inside assassin", LIST_CENSORED, "assassin's creed", false},
+		{"scunthorpe", LIST_CENSORED, "I live in Scunthorpe", false},
+		{"two single letters", LIST_CENSORED, "a s", false},
+		{"single letters in a sentence", LIST_CENSORED, "I am a cat", false},
+		{"separators between words", LIST_CENSORED, "pass... the salt", false},
+		{"substring alone", LIST_CENSORED, "crap", true},
+		{"substring inside a word", LIST_CENSORED, "crapola", true},
+		{"regex", LIST_CENSORED, "you noooob", true},
+		{"regex no match", LIST_CENSORED, "you know", false},
+		{"other list", LIST_CENSORED, "nazi", false},
+		{"blocked list", LIST_BLOCKED, "n4z1 stuff", true},
+		{"blocked list clean", LIST_BLOCKED, "fuck", false},
+		{"leetspeak next to letters", LIST_CENSORED, "what a sh17", true},
+		{"number", LIST_CENSORED, "455", false},
+		{"number in a sentence", LIST_CENSORED, "meet me in room 455", false},
+		{"price", LIST_CENSORED, "it was $455 in 1998", false},
+		{"number with punctuation", LIST_CENSORED, "call 555-1234!", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Has(tt.list, tt.input); got != tt.want {
+				t.Errorf("Has(%q, %q) = %v, want %v", tt.list, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCensor(t *testing.T) {
+	loadTestLists(t, testCensored, nil)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"oh fuck off", "oh f**k off"},
+		{"fuck fuck", "f**k f**k"},
+		{"F.u.c.k this", "F*****k this"},
+		{"f u c k this", "f*****k this"},
+		{"what the fuсk", "what the f**k"},
+		{"shït happens", "s**t happens"},
+		{"sh1t happens", "s**t happens"},
+		{"that's crap", "that's c**p"},
+		{"crapola", "c**pola"},
+		{"you noooob", "you n****b"},
+		{"pass the class", "pass the class"},
+		{"455", "455"},
+		{"8008", "8008"},
+		{"room 455, 8008 and $5.55", "room 455, 8008 and $5.55"},
+		{"you 455", "you 455"},
+		{"you a55", "you a*5"},
+		{"I live in Scunthorpe", "I live in Scunthorpe"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Censor(LIST_CENSORED, tt.input); got != tt.want {
+				t.Errorf("Censor(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEntry(t *testing.T) {
+	tests := []struct {
+		line     string
+		wantMode string
+		wantText string
+		wantErr  error
+	}{
+		{"fuck", MODE_WORD, "fuck", nil},
+		{"  fuck  ", MODE_WORD, "fuck", nil},
+		{"substring:crap", MODE_SUBSTRING, "crap", nil},
+		{"regex:n+o+b+", MODE_REGEX, "n+o+b+", nil},
+		{"unknown:word", MODE_WORD, "unknown:word", nil},
+		{"", MODE_WORD, "", ErrEmptyEntry},
+		{"regex:.*", MODE_REGEX, ".*", ErrMatchesAll},
+		{"...", MODE_WORD, "...", ErrNoLetters},
+		{strings.Repeat("a", MAX_ENTRY_LENGTH+1), MODE_WORD, strings.Repeat("a", MAX_ENTRY_LENGTH+1), ErrEntryTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			entry, err := ParseEntry(tt.line)
+			if err != tt.wantErr {
+				t.Fatalf("ParseEntry(%q) error = %v, want %v", tt.line, err, tt.wantErr)
+			}
+			if entry.Mode != tt.wantMode || entry.Text != tt.wantText {
+				t.Errorf("ParseEntry(%q) = %s %q, want %s %q", tt.line, entry.Mode, entry.Text, tt.wantMode, tt.wantText)
+			}
+		})
+	}
+}
+
+func mustEntry(t *testing.T, mode string, text string) Entry {
+	t.Helper()
+
+	entry, err := NewEntry(mode, text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func TestAddRemove(t *testing.T) {
+	loadTestLists(t, []string{"# comment", "fuck"}, []string{"nazi"})
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{"add", func() error { return Add(LIST_CENSORED, mustEntry(t, MODE_SUBSTRING, "crap")) }, nil},
+		{"add again", func() error { return Add(LIST_CENSORED, mustEntry(t, MODE_SUBSTRING, "CRAP")) }, ErrDuplicate},
+		{"other mode isn't a duplicate", func() error { return Add(LIST_CENSORED, mustEntry(t, MODE_WORD, "crap")) }, nil},
+		{"unknown list", func() error { return Add("friendly", mustEntry(t, MODE_WORD, "crap")) }, ErrUnknownList},
+		{"remove", func() error { return Remove(LIST_CENSORED, " Fuck ") }, nil},
+		{"remove again", func() error { return Remove(LIST_CENSORED, "fuck") }, ErrEntryNotFound},
+		{"remove from unknown list", func() error { return Remove("friendly", "nazi") }, ErrUnknownList},
+	}
+
+	for _, tt := range tests {
+		if err := tt.run(); err != tt.wantErr {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if got := Censor(LIST_CENSORED, "crappy fuck"); got != "c**ppy fuck" {
+		t.Errorf("Censor after the changes = %q", got)
+	}
+
+	lines, err := readLines(filePath(LIST_CENSORED))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"# comment", "substring:crap", "crap"}; strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("the file has %q, want %q", lines, want)
+	}
+
+	// What was saved is what gets loaded
+	LoadProfanityFilters()
+	if got := lo.Map(List(LIST_CENSORED), func(e Entry, _ int) string { return e.String() }); len(got) != 2 {
+		t.Errorf("loaded %v from the saved file", got)
+	}
+}
+
+func TestReloadChanged(t *testing.T) {
+	loadTestLists(t, []string{"fuck"}, []string{"nazi"})
+
+	if changed := ReloadChanged(); len(changed) != 0 {
+		t.Errorf("reloaded %v without changes", changed)
+	}
+
+	path := filePath(LIST_BLOCKED)
+	if err := os.WriteFile(path, []byte("badword\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Make sure the edit shows even where times only have whole seconds
+	later := modTime(path).Add(2 * time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if changed := ReloadChanged(); len(changed) != 1 || changed[0] != LIST_BLOCKED {
+		t.Fatalf("reloaded %v, want the blocked list", changed)
+	}
+	if Has(LIST_BLOCKED, "nazi") || !Has(LIST_BLOCKED, "a badword here") {
+		t.Error("the blocked list doesn't match the edited file")
+	}
+
+	// A missing file empties the list instead of keeping stale words
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	ReloadChanged()
+	if Has(LIST_BLOCKED, "a badword here") {
+		t.Error("words from a deleted file are still blocked")
+	}
+}
+
+var benchmarkMessages = []string{
+	"hey everyone, how is it going tonight?",
+	"anyone remember playing Duke Nukem 3D over a null modem cable",
+	"that was a f.u.c.k.i.n.g great match, gg",
+	"Windows 98 SE was the best one, fight me",
+}
+
+func loadBenchmarkLists(b *testing.B) {
+	config.Current.Profanity.Directory = "."
+	LoadProfanityFilters()
+
+	if len(List(LIST_BLOCKED)) == 0 || len(List(LIST_CENSORED)) == 0 {
+		b.Fatal("the word lists didn't load")
+	}
+}
+
+// What HasBlockedWords and ReplaceSensoredProfanity did before the
+// automaton, to compare against.
+func legacyHasBlockedWords(blocked []string, input string) bool {
+	for _, b := range blocked {
+		blockedRegex := regexp.MustCompile(`(?i)(^|[^a-zA-Z])` + b + `($|[^a-zA-Z])`)
+
+		if blockedRegex.MatchString(input) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func legacyReplaceSensoredProfanity(censored []string, input string) string {
+	words := strings.Split(input, " ")
+	words = lo.Map(words, func(word string, _ int) string {
+		if word == "" || !lo.Contains(censored, strings.ToLower(word)) {
+			return word
+		}
+		return censor(word)
+	})
+
+	return strings.Join(words, " ")
+}
+
+func entryTexts(list string) []string {
+	return lo.Map(List(list), func(e Entry, _ int) string { return regexp.QuoteMeta(e.Text) })
+}
+
+func BenchmarkHasBlockedWords(b *testing.B) {
+	loadBenchmarkLists(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		HasBlockedWords(benchmarkMessages[i%len(benchmarkMessages)])
+	}
+}
+
+func BenchmarkLegacyHasBlockedWords(b *testing.B) {
+	loadBenchmarkLists(b)
+	blocked := entryTexts(LIST_BLOCKED)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		legacyHasBlockedWords(blocked, benchmarkMessages[i%len(benchmarkMessages)])
+	}
+}
+
+func BenchmarkReplaceSensoredProfanity(b *testing.B) {
+	loadBenchmarkLists(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		ReplaceSensoredProfanity(benchmarkMessages[i%len(benchmarkMessages)])
+	}
+}
+
+func BenchmarkLegacyReplaceSensoredProfanity(b *testing.B) {
+	loadBenchmarkLists(b)
+	censored := lo.Map(List(LIST_CENSORED), func(e Entry, _ int) string { return strings.ToLower(e.Text) })
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		legacyReplaceSensoredProfanity(censored, benchmarkMessages[i%len(benchmarkMessages)])
+	}
+}
+
+func BenchmarkIsProfaneNickname(b *testing.B) {
+	loadBenchmarkLists(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		IsProfaneNickname("xX_Sn1per_Xx")
+	}
+}
@@ -0,0 +1,201 @@
+package profanity
+
+import (
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// matcher finds every word of a list in one pass over the text, it's an
+// Aho-Corasick automaton built over the normalized words.
+type matcher struct {
+	nodes    []matcherNode
+	patterns []pattern
+	// Where the root goes for each ASCII rune, it's where most of the
+	// text is looked at
+	root [utf8.RuneSelf]int
+	// Regexes can't go in the automaton
+	regexes []Entry
+}
+
+type matcherNode struct {
+	next map[rune]int
+	// Where to continue when the next rune doesn't match
+	fail int
+	// Patterns ending here, including the ones through fail
+	out []int
+}
+
+type pattern struct {
+	length    int
+	wholeWord bool
+}
+
+func newMatcher(entries []Entry) *matcher {
+	m := &matcher{nodes: []matcherNode{{next: map[rune]int{}}}}
+
+	for _, entry := range entries {
+		if entry.Mode == MODE_REGEX {
+			m.regexes = append(m.regexes, entry)
+			continue
+		}
+
+		m.insert(normalizeWord(entry.Text), entry.Mode == MODE_WORD)
+	}
+
+	m.link()
+	return m
+}
+
+func (m *matcher) insert(word []rune, wholeWord bool) {
+	if len(word) == 0 {
+		return
+	}
+
+	current := 0
+	for _, r := range word {
+		next, found := m.nodes[current].next[r]
+		if !found {
+			next = len(m.nodes)
+			m.nodes = append(m.nodes, matcherNode{next: map[rune]int{}})
+			m.nodes[current].next[r] = next
+		}
+		current = next
+	}
+
+	m.nodes[current].out = append(m.nodes[current].out, len(m.patterns))
+	m.patterns = append(m.patterns, pattern{length: len(word), wholeWord: wholeWord})
+}
+
+// link sets the fail links breadth first, so shorter suffixes are done
+// before the longer ones that point at them.
+func (m *matcher) link() {
+	queue := []int{}
+	for r, child := range m.nodes[0].next {
+		queue = append(queue, child)
+		if r >= 0 && r < utf8.RuneSelf {
+			m.root[r] = child
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for r, child := range m.nodes[current].next {
+			fail := m.nodes[current].fail
+			for {
+				if next, found := m.nodes[fail].next[r]; found && next != child {
+					fail = next
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+
+			m.nodes[child].fail = fail
+			m.nodes[child].out = append(m.nodes[child].out, m.nodes[fail].out...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+func isWordBoundary(runes []rune, start int, end int) bool {
+	return (start == 0 || !unicode.IsLetter(runes[start-1])) && (end == len(runes) || !unicode.IsLetter(runes[end]))
+}
+
+// find returns the byte ranges of the original text that matched, stops
+// at the first one if firstOnly is set.
+func (m *matcher) find(original string, text normalized, firstOnly bool) [][]int {
+	found := [][]int{}
+	if m == nil {
+		return found
+	}
+
+	current := 0
+
+	for i, r := range text.runes {
+		for {
+			if current == 0 && r >= 0 && r < utf8.RuneSelf {
+				current = m.root[r]
+				break
+			}
+			if next, ok := m.nodes[current].next[r]; ok {
+				current = next
+				break
+			}
+			if current == 0 {
+				break
+			}
+			current = m.nodes[current].fail
+		}
+
+		for _, p := range m.nodes[current].out {
+			start := i - m.patterns[p].length + 1
+			if m.patterns[p].wholeWord && !isWordBoundary(text.runes, start, i+1) {
+				continue
+			}
+
+			found = append(found, []int{text.starts[start], text.ends[i]})
+			if firstOnly {
+				return found
+			}
+		}
+	}
+
+	for _, entry := range m.regexes {
+		// Plain text for patterns about punctuation or digits, folded for the rest
+		for _, loc := range entry.expr.FindAllStringIndex(original, -1) {
+			found = append(found, loc)
+		}
+		for _, loc := range entry.expr.FindAllStringIndex(string(text.runes), -1) {
+			found = append(found, text.originalRange(loc))
+		}
+
+		if firstOnly && len(found) > 0 {
+			return found[:1]
+		}
+	}
+
+	return found
+}
+
+// originalRange turns byte offsets in the folded text into the ones in
+// the original text.
+func (n normalized) originalRange(loc []int) []int {
+	offset, start, end := 0, -1, -1
+	for i, r := range n.runes {
+		if offset == loc[0] {
+			start = n.starts[i]
+		}
+		offset += len(string(r))
+		if offset == loc[1] {
+			end = n.ends[i]
+			break
+		}
+	}
+
+	if start < 0 || end < 0 {
+		return []int{0, 0}
+	}
+	return []int{start, end}
+}
+
+// mergeRanges sorts the ranges and joins the overlapping ones.
+func mergeRanges(ranges [][]int) [][]int {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	merged := [][]int{}
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && r[0] < merged[last][1] {
+			merged[last][1] = max(merged[last][1], r[1])
+			continue
+		}
+		merged = append(merged, []int{r[0], r[1]})
+	}
+
+	return merged
+}
@@ -0,0 +1,202 @@
+package profanity
+
+import (
+	"retro-chat-rooms/helpers"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Characters that stand in for letters
+var leetspeak = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '9': 'g',
+	'@': 'a', '$': 's', '+': 't',
+}
+
+// What each ASCII character folds to, most text is only these. The
+// leetspeak ones only apply inside words, "455" is a number, "a55" isn't.
+var asciiFolds, asciiLeetFolds = func() (folds [utf8.RuneSelf]rune, leet [utf8.RuneSelf]rune) {
+	for r := range folds {
+		folds[r] = rune(r)
+		if r >= 'A' && r <= 'Z' {
+			folds[r] += 'a' - 'A'
+		}
+		leet[r] = folds[r]
+		if look, found := leetspeak[rune(r)]; found {
+			leet[r] = look
+		}
+	}
+	return folds, leet
+}()
+
+// normalized is the text the matcher looks at, each rune remembers
+// where it came from in the original text.
+type normalized struct {
+	runes []rune
+	// Byte offsets of each rune in the original text
+	starts []int
+	ends   []int
+}
+
+func newNormalized(size int) normalized {
+	return normalized{
+		runes:  make([]rune, 0, size),
+		starts: make([]int, 0, size),
+		ends:   make([]int, 0, size),
+	}
+}
+
+func (n *normalized) add(r rune, start int, end int) {
+	n.runes = append(n.runes, r)
+	n.starts = append(n.starts, start)
+	n.ends = append(n.ends, end)
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsSpace(r)
+}
+
+// foldRune turns a character into the plain lowercase letters it looks
+// like, leetspeak included when leet is set.
+func foldRune(r rune, folded []rune, leet bool) []rune {
+	folded = folded[:0]
+
+	// Plain ASCII is most of the text, skip the unicode tables for it
+	if r >= 0 && r < utf8.RuneSelf {
+		if leet {
+			return append(folded, asciiLeetFolds[r])
+		}
+		return append(folded, asciiFolds[r])
+	}
+
+	folded = helpers.FoldRune(r, folded)
+
+	for i, f := range folded {
+		if look, found := leetspeak[f]; found && leet {
+			folded[i] = look
+		}
+	}
+
+	return folded
+}
+
+// hasLetterBefore tells if the text has a letter before the next space.
+func hasLetterBefore(text string) bool {
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize folds the text so obfuscated words look like the plain ones,
+// ex: "F.u.Ç.k" and "f u c k" both become "fuck".
+func normalize(text string) normalized {
+	folded := newNormalized(len(text))
+	buffer := make([]rune, 0, 4)
+	// Whether the word being read has letters, so its digits and
+	// symbols stand in for some
+	inWord, wordStart := false, true
+
+	for i, r := range text {
+		end := i + utf8.RuneLen(r)
+		if unicode.IsSpace(r) {
+			inWord, wordStart = false, true
+		} else if wordStart {
+			inWord, wordStart = hasLetterBefore(text[i:]), false
+		}
+		buffer = foldRune(r, buffer, inWord)
+		for _, f := range buffer {
+			folded.add(f, i, end)
+		}
+	}
+
+	if !slices.ContainsFunc(folded.runes, isSeparator) {
+		return joinSpacedLetters(folded)
+	}
+
+	// Separators inside a word are dropped, anywhere else they split words
+	joined := newNormalized(len(folded.runes))
+	for i, r := range folded.runes {
+		if !isSeparator(r) {
+			joined.add(r, folded.starts[i], folded.ends[i])
+			continue
+		}
+
+		next := i + 1
+		for next < len(folded.runes) && isSeparator(folded.runes[next]) {
+			next++
+		}
+
+		inWord := len(joined.runes) > 0 && unicode.IsLetter(joined.runes[len(joined.runes)-1]) &&
+			next < len(folded.runes) && unicode.IsLetter(folded.runes[next])
+
+		if !inWord {
+			joined.add(' ', folded.starts[i], folded.ends[i])
+		}
+	}
+
+	return joinSpacedLetters(joined)
+}
+
+// joinSpacedLetters puts words spelled out one letter at a time back
+// together, it takes at least 3 letters so "a b" stays as it is.
+func joinSpacedLetters(text normalized) normalized {
+	isSingle := func(i int) bool {
+		return i < len(text.runes) && !unicode.IsSpace(text.runes[i]) &&
+			(i == 0 || unicode.IsSpace(text.runes[i-1])) &&
+			(i+1 == len(text.runes) || unicode.IsSpace(text.runes[i+1]))
+	}
+
+	// Most messages have no words spelled out, keep those as they are
+	singles := 0
+	for i := range text.runes {
+		if isSingle(i) {
+			singles++
+		}
+	}
+	if singles < 3 {
+		return text
+	}
+
+	result := newNormalized(len(text.runes))
+	for i := 0; i < len(text.runes); {
+		// Count the letters in a row, each followed by spaces
+		count, end := 0, i
+		for isSingle(end) {
+			count++
+			next := end + 1
+			for next < len(text.runes) && unicode.IsSpace(text.runes[next]) {
+				next++
+			}
+			if !isSingle(next) {
+				end++
+				break
+			}
+			end = next
+		}
+
+		if count >= 3 {
+			for j := i; j < end; j++ {
+				if !unicode.IsSpace(text.runes[j]) {
+					result.add(text.runes[j], text.starts[j], text.ends[j])
+				}
+			}
+			i = end
+			continue
+		}
+
+		result.add(text.runes[i], text.starts[i], text.ends[i])
+		i++
+	}
+
+	return result
+}
+
+func normalizeWord(word string) []rune {
+	return normalize(word).runes
+}
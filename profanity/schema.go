@@ -0,0 +1,10 @@
+package profanity
+
+import "regexp"
+
+type Entry struct {
+	Text string
+	Mode string
+
+	expr *regexp.Regexp
+}
@@ -0,0 +1,19 @@
+package reports
+
+const REPORTS_DOCUMENT = "reports"
+
+const (
+	STATUS_OPEN      = "open"
+	STATUS_DISMISSED = "dismissed"
+	STATUS_ACTIONED  = "actioned"
+)
+
+const (
+	// Messages before the reported one that are kept with it
+	CONTEXT_MESSAGES  = 5
+	MAX_REASON_LENGTH = 200
+	// Open reports one user can have at once
+	MAX_OPEN_PER_REPORTER = 5
+	// Closed reports are dropped after this
+	KEEP_CLOSED_DAYS = 30
+)
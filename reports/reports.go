@@ -0,0 +1,234 @@
+package reports
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/pubsub"
+	"retro-chat-rooms/roles"
+	"retro-chat-rooms/storage"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+var (
+	reports []Report = make([]Report, 0)
+
+	loadOnce = sync.Once{}
+	mutex    = sync.Mutex{}
+)
+
+// Events gets a ReportSubmittedEvent for every new report.
+var Events = pubsub.NewPubsub()
+
+var (
+	ErrMessageNotFound = errors.New("that message is gone")
+	ErrOwnMessage      = errors.New("you can't report your own message")
+	ErrAlreadyReported = errors.New("you already reported that message")
+	ErrTooManyReports  = errors.New("you have too many reports waiting for a moderator")
+	ErrReasonTooLong   = fmt.Errorf("the reason can have at most %d characters", MAX_REASON_LENGTH)
+	ErrReportNotFound  = errors.New("report not found")
+	ErrReportClosed    = errors.New("the report was already handled")
+)
+
+// ensureLoaded expects mutex to be locked.
+func ensureLoaded() {
+	loadOnce.Do(func() {
+		_, err := storage.Current.Load(REPORTS_DOCUMENT, &reports)
+		if err != nil {
+			log.Printf("Error loading reports: %v", err)
+		}
+	})
+}
+
+// save drops old closed reports and writes the rest, expects mutex to be locked.
+func save() {
+	cutoff := time.Now().UTC().AddDate(0, 0, -KEEP_CLOSED_DAYS)
+	reports = lo.Filter(reports, func(r Report, _ int) bool {
+		return r.Status == STATUS_OPEN || r.ResolvedAt.After(cutoff)
+	})
+
+	err := storage.Current.Save(REPORTS_DOCUMENT, reports)
+	if err != nil {
+		log.Printf("Error saving reports: %v", err)
+	}
+}
+
+func toLine(m *chat.ChatMessage) Line {
+	line := Line{Time: m.Time, Message: m.Message}
+
+	if from := m.GetFrom(); from != nil {
+		line.Nickname = html.UnescapeString(from.Nickname)
+	}
+
+	return line
+}
+
+// getContext returns the messages the reporter saw right before the reported one,
+// Discord users have no thread of their own so the room's is used.
+func getContext(reporterId string, roomId string, messageId string) []Line {
+	isReported := func(m *chat.ChatMessage) bool {
+		return m != nil && m.ID == messageId
+	}
+
+	messages, _ := chat.GetUserMessageList(reporterId)
+	_, index, found := lo.FindIndexOf(messages, isReported)
+
+	if !found {
+		messages = chat.GetRoomMessageHistory(roomId)
+		_, index, found = lo.FindIndexOf(messages, isReported)
+	}
+
+	if !found {
+		return make([]Line, 0)
+	}
+
+	before := lo.Filter(messages[:index], func(m *chat.ChatMessage, _ int) bool {
+		return m != nil && !m.IsSystemMessage && (!m.Privately || m.To == reporterId || m.From == reporterId)
+	})
+
+	return lo.Map(lo.Subset(before, -CONTEXT_MESSAGES, CONTEXT_MESSAGES), func(m *chat.ChatMessage, _ int) Line {
+		return toLine(m)
+	})
+}
+
+// Submit files a report about a message the reporter received.
+func Submit(reporter chat.ChatUser, ip string, messageId string, reason string) (Report, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > MAX_REASON_LENGTH {
+		return Report{}, ErrReasonTooLong
+	}
+
+	message, found := chat.FindUserMessage(reporter.ID, messageId)
+	if !found {
+		message, found = chat.FindRoomMessage(reporter.RoomId, messageId)
+	}
+
+	if !found || message.IsSystemMessage {
+		return Report{}, ErrMessageNotFound
+	}
+
+	if message.From == reporter.ID {
+		return Report{}, ErrOwnMessage
+	}
+
+	report := Report{
+		ID:         uuid.NewString(),
+		Time:       time.Now().UTC(),
+		RoomID:     message.RoomID,
+		MessageID:  message.ID,
+		Reporter:   html.UnescapeString(reporter.Nickname),
+		ReporterID: reporter.ID,
+		ReporterIP: ip,
+		Reason:     reason,
+		AuthorID:   message.From,
+		Message:    toLine(message),
+		Context:    getContext(reporter.ID, message.RoomID, message.ID),
+		Status:     STATUS_OPEN,
+	}
+
+	report.Author = report.Message.Nickname
+
+	if author := message.GetFrom(); author != nil {
+		report.AuthorIP = author.IP
+	}
+
+	mutex.Lock()
+	ensureLoaded()
+
+	open := lo.Filter(reports, func(r Report, _ int) bool {
+		return r.Status == STATUS_OPEN && r.ReporterID == reporter.ID
+	})
+
+	if lo.ContainsBy(open, func(r Report) bool { return r.MessageID == messageId }) {
+		mutex.Unlock()
+		return Report{}, ErrAlreadyReported
+	}
+
+	if len(open) >= MAX_OPEN_PER_REPORTER {
+		mutex.Unlock()
+		return Report{}, ErrTooManyReports
+	}
+
+	reports = append(reports, report)
+	save()
+	mutex.Unlock()
+
+	notifyModerators(report)
+	Events.Publish(ReportSubmittedEvent{Report: report})
+
+	return report, nil
+}
+
+// notifyModerators tells everyone online who can act on the report.
+func notifyModerators(r Report) {
+	notice := fmt.Sprintf(
+		"{nickname}, %s reported a message from %s: <i>%s</i>. Check the reports in the admin area.",
+		template.HTMLEscapeString(r.Reporter),
+		template.HTMLEscapeString(r.Author),
+		template.HTMLEscapeString(r.Reason),
+	)
+
+	for _, user := range chat.GetAllUsers() {
+		if roles.Has(roles.RoleFor(user, r.RoomID), roles.PERM_KICK) && !user.IsDiscordUser() {
+			chat.SendSystemNotice(user, notice)
+		}
+	}
+}
+
+// List returns the reports newest first, only the open ones unless all is set.
+func List(all bool) []Report {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	list := lo.Filter(reports, func(r Report, _ int) bool {
+		return all || r.Status == STATUS_OPEN
+	})
+
+	return lo.Reverse(list)
+}
+
+func Get(id string) (Report, bool) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	return lo.Find(reports, func(r Report) bool {
+		return r.ID == id
+	})
+}
+
+// Resolve closes an open report.
+func Resolve(id string, status string, resolvedBy string, resolution string) (Report, error) {
+	defer mutex.Unlock()
+	mutex.Lock()
+	ensureLoaded()
+
+	_, index, found := lo.FindIndexOf(reports, func(r Report) bool {
+		return r.ID == id
+	})
+
+	if !found {
+		return Report{}, ErrReportNotFound
+	}
+
+	if reports[index].Status != STATUS_OPEN {
+		return Report{}, ErrReportClosed
+	}
+
+	reports[index].Status = status
+	reports[index].ResolvedBy = resolvedBy
+	reports[index].ResolvedAt = time.Now().UTC()
+	reports[index].Resolution = resolution
+	save()
+
+	return reports[index], nil
+}
@@ -0,0 +1,34 @@
+package reports
+
+import "time"
+
+// Line is a message as the reporter saw it, in plain text.
+type Line struct {
+	Time     time.Time `json:"time"`
+	Nickname string    `json:"nickname"`
+	Message  string    `json:"message"`
+}
+
+type Report struct {
+	ID         string    `json:"id"`
+	Time       time.Time `json:"time"`
+	RoomID     string    `json:"roomId"`
+	MessageID  string    `json:"messageId"`
+	Reporter   string    `json:"reporter"`
+	ReporterID string    `json:"reporterId"`
+	ReporterIP string    `json:"reporterIp,omitempty"`
+	Reason     string    `json:"reason"`
+	Author     string    `json:"author"`
+	AuthorID   string    `json:"authorId"`
+	AuthorIP   string    `json:"authorIp,omitempty"`
+	Message    Line      `json:"message"`
+	Context    []Line    `json:"context"`
+	Status     string    `json:"status"`
+	ResolvedBy string    `json:"resolvedBy,omitempty"`
+	ResolvedAt time.Time `json:"resolvedAt,omitempty"`
+	Resolution string    `json:"resolution,omitempty"`
+}
+
+type ReportSubmittedEvent struct {
+	Report Report
+}
@@ -0,0 +1,57 @@
+package roles
+
+import "retro-chat-rooms/chat"
+
+const (
+	PERM_POLLS        = "polls"
+	PERM_KICK         = "kick"
+	PERM_MUTE         = "mute"
+	PERM_PURGE        = "purge"
+	PERM_BAN          = "ban"
+	PERM_CONSOLE      = "console"
+	PERM_EXPORT       = "export"
+	PERM_ASSIGN_ROLES = "assign-roles"
+	// Slow mode and other room settings
+	PERM_ROOM_SETTINGS = "room-settings"
+	// Censored and blocked words
+	PERM_WORD_FILTERS = "word-filters"
+)
+
+// Higher ranks can act on lower ones, never the other way around
+var ranks = map[string]int{
+	"":                         0,
+	chat.ROLE_VOICED:           1,
+	chat.ROLE_ROOM_OPERATOR:    2,
+	chat.ROLE_GLOBAL_MODERATOR: 3,
+	chat.ROLE_OWNER:            4,
+}
+
+var permissions = map[string][]string{
+	chat.ROLE_VOICED: {PERM_POLLS},
+	chat.ROLE_ROOM_OPERATOR: {
+		PERM_POLLS, PERM_KICK, PERM_MUTE, PERM_PURGE, PERM_CONSOLE, PERM_ASSIGN_ROLES, PERM_ROOM_SETTINGS,
+	},
+	chat.ROLE_GLOBAL_MODERATOR: {
+		PERM_POLLS, PERM_KICK, PERM_MUTE, PERM_PURGE, PERM_CONSOLE, PERM_BAN, PERM_EXPORT, PERM_ASSIGN_ROLES,
+		PERM_ROOM_SETTINGS, PERM_WORD_FILTERS,
+	},
+	chat.ROLE_OWNER: {
+		PERM_POLLS, PERM_KICK, PERM_MUTE, PERM_PURGE, PERM_CONSOLE, PERM_BAN, PERM_EXPORT, PERM_ASSIGN_ROLES,
+		PERM_ROOM_SETTINGS, PERM_WORD_FILTERS,
+	},
+}
+
+var badges = map[string]string{
+	chat.ROLE_VOICED:           "Voice",
+	chat.ROLE_ROOM_OPERATOR:    "Op",
+	chat.ROLE_GLOBAL_MODERATOR: "Mod",
+	chat.ROLE_OWNER:            "Owner",
+}
+
+// Prefix for the session user ID of moderators from the config
+const STAFF_USER_ID_PREFIX = "staff:"
+
+// How long a moderator stays logged in to the admin area
+const DEFAULT_STAFF_SESSION_HOURS = 12
+
+const STAFF_SESSION_TOKEN_BYTES = 32
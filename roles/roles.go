@@ -0,0 +1,229 @@
+package roles
+
+import (
+	"errors"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/lockout"
+	"retro-chat-rooms/passwords"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+var (
+	ErrNotAllowed  = errors.New("you are not allowed to do that")
+	ErrInvalidRole = errors.New("unknown role, use voiced, room-operator, global-moderator or none")
+
+	ErrBadCredentials = errors.New("wrong nickname or password")
+	ErrLockedOut      = errors.New("too many failed logins")
+)
+
+func IsValid(role string) bool {
+	_, found := ranks[role]
+	return found && role != ""
+}
+
+func Outranks(role string, other string) bool {
+	return ranks[role] > ranks[other]
+}
+
+func Has(role string, perm string) bool {
+	return lo.Contains(permissions[role], perm)
+}
+
+func Can(user chat.ChatUser, perm string) bool {
+	return Has(user.Role, perm)
+}
+
+func Badge(role string) string {
+	return badges[role]
+}
+
+func isGlobal(role string) bool {
+	return role == chat.ROLE_OWNER || role == chat.ROLE_GLOBAL_MODERATOR
+}
+
+// RoleFor returns the role the user has when acting in a room, room
+// operators and voiced users only have theirs in the room they are in.
+func RoleFor(user chat.ChatUser, roomId string) string {
+	if user.RoomId == roomId || isGlobal(user.Role) {
+		return user.Role
+	}
+	return ""
+}
+
+// CanModerate tells if someone with the role may use perm on the target,
+// nobody can act on someone of the same or a higher rank.
+func CanModerate(role string, target chat.ChatUser, perm string) bool {
+	return Has(role, perm) && Outranks(role, target.Role)
+}
+
+// RoleIn returns the role a moderator from the config has in the room.
+func RoleIn(staff config.ModeratorConfig, roomId string) string {
+	if isGlobal(staff.Role) || len(staff.Rooms) == 0 || lo.Contains(staff.Rooms, roomId) {
+		return staff.Role
+	}
+	return ""
+}
+
+// allStaff lists everyone with a role in the config, owner first.
+func allStaff() []config.ModeratorConfig {
+	owner := config.Current.OwnerChatUser
+
+	staff := []config.ModeratorConfig{{
+		Nickname:  owner.Nickname,
+		Password:  owner.Password,
+		DiscordId: owner.DiscordId,
+		Color:     owner.Color,
+		Role:      chat.ROLE_OWNER,
+	}}
+
+	for _, m := range config.Current.Moderators {
+		// Nobody else can be an owner
+		if IsValid(m.Role) && m.Role != chat.ROLE_OWNER {
+			staff = append(staff, m)
+		}
+	}
+
+	return staff
+}
+
+func FindStaff(nickname string) (config.ModeratorConfig, bool) {
+	return lo.Find(allStaff(), func(m config.ModeratorConfig) bool {
+		return m.Nickname != "" && strings.EqualFold(m.Nickname, strings.TrimSpace(nickname))
+	})
+}
+
+func Authenticate(nickname string, password string) (config.ModeratorConfig, bool) {
+	staff, found := FindStaff(nickname)
+
+	if !found || !passwords.Verify(staff.Password, password) {
+		return config.ModeratorConfig{}, false
+	}
+
+	return staff, true
+}
+
+// Login authenticates a moderator, locking out the IP and the
+// account after too many wrong passwords.
+func Login(nickname string, password string, ip string) (config.ModeratorConfig, error) {
+	if _, locked := lockout.Remaining(ip, nickname); locked {
+		return config.ModeratorConfig{}, ErrLockedOut
+	}
+
+	staff, authenticated := Authenticate(nickname, password)
+
+	if !authenticated {
+		if lockout.RecordFailure(ip, nickname) {
+			return config.ModeratorConfig{}, ErrLockedOut
+		}
+		return config.ModeratorConfig{}, ErrBadCredentials
+	}
+
+	lockout.RecordSuccess(ip, nickname)
+
+	return staff, nil
+}
+
+// DescribeLoginError tells the moderator why Login failed.
+func DescribeLoginError(nickname string, ip string, err error) string {
+	if err == ErrLockedOut {
+		remaining, _ := lockout.Remaining(ip, nickname)
+		return "Too many failed logins, try again in " + chat.FormatRemainingTime(remaining) + "."
+	}
+	return "Wrong nickname or password."
+}
+
+// ForDiscordUser returns the role a Discord user has in the room.
+func ForDiscordUser(discordId string, roomId string) string {
+	staff, found := lo.Find(allStaff(), func(m config.ModeratorConfig) bool {
+		return m.DiscordId != "" && m.DiscordId == discordId
+	})
+
+	if !found {
+		return ""
+	}
+
+	return RoleIn(staff, roomId)
+}
+
+// ForDiscordMember returns the highest role a Discord user has in the
+// room, from the moderators list or from their Discord roles.
+func ForDiscordMember(discordId string, discordRoles []string, roomId string) string {
+	role := ForDiscordUser(discordId, roomId)
+
+	for _, mapped := range config.Current.DiscordRoles {
+		if !lo.Contains(discordRoles, mapped.ID) || !IsValid(mapped.Role) || mapped.Role == chat.ROLE_OWNER {
+			continue
+		}
+
+		inRoom := RoleIn(config.ModeratorConfig{Role: mapped.Role, Rooms: mapped.Rooms}, roomId)
+		if Outranks(inRoom, role) {
+			role = inRoom
+		}
+	}
+
+	return role
+}
+
+// StaffUserId is the user ID a moderator from the config always gets.
+func StaffUserId(staff config.ModeratorConfig) string {
+	if staff.Role == chat.ROLE_OWNER {
+		return config.Current.OwnerChatUser.Id
+	}
+	return STAFF_USER_ID_PREFIX + strings.ToLower(staff.Nickname)
+}
+
+// SignIn puts an authenticated moderator in the room and returns
+// their combined ID.
+func SignIn(staff config.ModeratorConfig, roomId string, clientInfo chat.ClientInfo, ip string) string {
+	if staff.Role == chat.ROLE_OWNER {
+		return chat.RegisterAdmin(roomId, clientInfo, ip)
+	}
+
+	color := staff.Color
+	if color == "" {
+		color = chat.USER_COLOR_BLACK
+	}
+
+	return chat.RegisterStaff(chat.ChatUser{
+		ID:        chat.GetCombinedId(roomId, StaffUserId(staff)),
+		Nickname:  staff.Nickname,
+		Color:     color,
+		DiscordId: "",
+		RoomId:    roomId,
+		Role:      RoleIn(staff, roomId),
+		Client:    clientInfo,
+		IP:        ip,
+	})
+}
+
+// Assign gives the target a role for as long as they stay online,
+// permanent roles go in the config.
+func Assign(actor string, actorRole string, target chat.ChatUser, role string) error {
+	if role != "" && (!IsValid(role) || role == chat.ROLE_OWNER) {
+		return ErrInvalidRole
+	}
+
+	if !CanModerate(actorRole, target, PERM_ASSIGN_ROLES) || !Outranks(actorRole, role) {
+		return ErrNotAllowed
+	}
+
+	chat.SetUserRole(target.ID, role)
+
+	reason := role
+	if reason == "" {
+		reason = "none"
+	}
+
+	chat.PublishModerationEvent(chat.ChatModerationEvent{
+		Action: chat.MODERATION_ROLE,
+		RoomID: target.RoomId,
+		Actor:  actor,
+		Target: target,
+		Reason: reason,
+	})
+
+	return nil
+}
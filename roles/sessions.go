@@ -0,0 +1,108 @@
+package roles
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"retro-chat-rooms/config"
+	"sync"
+	"time"
+)
+
+// Staff logins live on the server, the session cookie only holds a
+// random token pointing to one.
+type staffSession struct {
+	Nickname string
+	Expires  time.Time
+	// Sent back by the admin forms, other sites can't know it
+	FormToken string
+}
+
+var (
+	sessionsMutex sync.Mutex
+	staffSessions = map[string]staffSession{}
+)
+
+func getStaffSessionDuration() time.Duration {
+	hours := config.Current.StaffSessionHours
+	if hours == 0 {
+		hours = DEFAULT_STAFF_SESSION_HOURS
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+func newToken() string {
+	buffer := make([]byte, STAFF_SESSION_TOKEN_BYTES)
+	if _, err := rand.Read(buffer); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buffer)
+}
+
+// StartSession returns the token for a moderator who just logged in.
+func StartSession(staff config.ModeratorConfig) string {
+	token := newToken()
+
+	defer sessionsMutex.Unlock()
+	sessionsMutex.Lock()
+
+	staffSessions[token] = staffSession{
+		Nickname:  staff.Nickname,
+		Expires:   time.Now().Add(getStaffSessionDuration()),
+		FormToken: newToken(),
+	}
+
+	return token
+}
+
+// SessionFormToken returns what the admin forms of the session have to
+// send back, empty if the session is gone.
+func SessionFormToken(token string) string {
+	defer sessionsMutex.Unlock()
+	sessionsMutex.Lock()
+
+	session, found := staffSessions[token]
+	if !found || time.Now().After(session.Expires) {
+		return ""
+	}
+	return session.FormToken
+}
+
+// SessionStaff returns who the token belongs to, as long as it hasn't
+// expired and they are still in the config.
+func SessionStaff(token string) (config.ModeratorConfig, bool) {
+	sessionsMutex.Lock()
+	session, found := staffSessions[token]
+	sessionsMutex.Unlock()
+
+	if !found || token == "" || time.Now().After(session.Expires) {
+		return config.ModeratorConfig{}, false
+	}
+
+	return FindStaff(session.Nickname)
+}
+
+// EndSession logs the moderator out.
+func EndSession(token string) {
+	defer sessionsMutex.Unlock()
+	sessionsMutex.Lock()
+
+	delete(staffSessions, token)
+}
+
+// EvictSessions forgets expired logins, returns how many were dropped.
+func EvictSessions() int {
+	defer sessionsMutex.Unlock()
+	sessionsMutex.Lock()
+
+	now := time.Now()
+	evicted := 0
+
+	for token, session := range staffSessions {
+		if now.After(session.Expires) {
+			delete(staffSessions, token)
+			evicted++
+		}
+	}
+
+	return evicted
+}
@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/export"
+	"retro-chat-rooms/roles"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+// canSeeAuditEntry keeps room operators to their own rooms, entries
+// that aren't about a room are for whoever can ban.
+func canSeeAuditEntry(staff config.ModeratorConfig, e audit.Entry) bool {
+	if e.RoomID == "" {
+		return roles.Has(staff.Role, roles.PERM_BAN)
+	}
+	return roles.Has(roles.RoleIn(staff, e.RoomID), roles.PERM_CONSOLE)
+}
+
+// GetAdminAudit lists moderation actions,
+// ex: /admin/audit?action=ban&room=general&actor=&target=&from=2025-01-01&to=2025-01-31
+func GetAdminAudit(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getSessionStaff(session)
+
+	if !isStaff {
+		c.Redirect(http.StatusFound, BustCache("/admin-login"))
+		return
+	}
+
+	filter := audit.Filter{
+		Action: c.Query("action"),
+		RoomID: c.Query("room"),
+		Actor:  c.Query("actor"),
+		Target: c.Query("target"),
+	}
+
+	errorMessage := ""
+
+	// Without dates everything is searched
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to, err := export.ParseRange(c.Query("from"), c.Query("to"))
+		if err != nil {
+			errorMessage = err.Error()
+		}
+		filter.From = from
+		filter.To = to
+	}
+
+	entries, err := audit.Query(filter)
+	if err != nil {
+		errorMessage = "Couldn't read the audit log."
+	}
+
+	entries = lo.Filter(entries, func(e audit.Entry, _ int) bool {
+		return canSeeAuditEntry(staff, e)
+	})
+
+	c.HTML(http.StatusOK, "admin-audit.html", gin.H{
+		"Entries": entries,
+		"Actions": audit.ACTIONS,
+		"Rooms":   chat.GetAllRooms(),
+		"Filter":  filter,
+		"From":    c.Query("from"),
+		"To":      c.Query("to"),
+		"Error":   errorMessage,
+		"Max":     audit.MAX_QUERY_RESULTS,
+	})
+}
@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/bans"
+	"retro-chat-rooms/roles"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func PostAdminBan(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+
+	if !isStaff || !roles.Has(staff.Role, roles.PERM_BAN) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	// Empty or 0 bans forever
+	minutes, err := strconv.Atoi(c.PostForm("min"))
+	if err != nil || minutes < 0 {
+		minutes = 0
+	}
+
+	add := bans.Add
+	if c.PostForm("shadow") != "" {
+		add = bans.AddShadow
+	}
+
+	ban, err := add(
+		c.PostForm("type"),
+		c.PostForm("value"),
+		c.PostForm("reason"),
+		staff.Nickname,
+		time.Duration(minutes)*time.Minute,
+	)
+
+	if err != nil {
+		redirectToAdmin(c, "Couldn't add the ban: "+err.Error()+".")
+		return
+	}
+
+	audit.Record(audit.Entry{
+		Action: audit.ACTION_BAN_ADD,
+		Actor:  staff.Nickname,
+		Target: ban.Type + " " + ban.Value,
+		Reason: ban.Reason,
+	})
+
+	redirectToAdmin(c, "Banned "+ban.Type+" "+ban.Value+".")
+}
+
+func PostAdminUnban(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+
+	if !isStaff || !roles.Has(staff.Role, roles.PERM_BAN) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	ban, found := bans.Remove(c.PostForm("id"))
+	if !found {
+		redirectToAdmin(c, "That ban doesn't exist anymore.")
+		return
+	}
+
+	audit.Record(audit.Entry{
+		Action: audit.ACTION_BAN_LIFT,
+		Actor:  staff.Nickname,
+		Target: ban.Type + " " + ban.Value,
+	})
+
+	redirectToAdmin(c, "The ban was lifted.")
+}
@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/export"
+	"retro-chat-rooms/history"
+	"retro-chat-rooms/roles"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAdminExport dumps a room's stored history,
+// ex: /admin/export/general?format=html&from=2025-01-01&to=2025-01-31
+func GetAdminExport(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getSessionStaff(session)
+	room, found := chat.GetSingleRoom(c.Param("id"))
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	if !isStaff || !roles.Has(roles.RoleIn(staff, room.ID), roles.PERM_EXPORT) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	format := c.DefaultQuery("format", export.FORMAT_TEXT)
+
+	if !export.IsValidFormat(format) {
+		c.String(http.StatusBadRequest, "Unknown format, use one of: txt, html, irc, jsonl.")
+		return
+	}
+
+	from, to, err := export.ParseRange(c.Query("from"), c.Query("to"))
+
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	records, err := history.Query(room.ID, from, to, c.Query("private") == "on")
+
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Couldn't read the room history.")
+		return
+	}
+
+	transcript := export.Transcript{
+		RoomID:   room.ID,
+		RoomName: room.Name,
+		Color:    room.Color,
+		From:     from,
+		To:       to,
+		Records:  records,
+	}
+
+	// Render it all first, a failure halfway shouldn't
+	// send a truncated file that looks complete
+	var body bytes.Buffer
+	if err := export.Write(&body, transcript, format); err != nil {
+		log.Printf("Error exporting the history of %s: %v", room.ID, err)
+		c.String(http.StatusInternalServerError, "Couldn't export the room history.")
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+export.FileName(transcript, format)+`"`)
+	c.Data(http.StatusOK, export.ContentType(format), body.Bytes())
+}
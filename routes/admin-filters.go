@@ -0,0 +1,101 @@
+package routes
+
+import (
+	"net/http"
+	"net/url"
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/profanity"
+	"retro-chat-rooms/roles"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type adminFilterList struct {
+	Name    string
+	Entries []profanity.Entry
+}
+
+func redirectToAdminFilters(c *gin.Context, done string) {
+	c.Redirect(http.StatusFound, "/admin/filters?done="+url.QueryEscape(done))
+}
+
+func GetAdminFilters(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getSessionStaff(session)
+
+	if !isStaff {
+		c.Redirect(http.StatusFound, BustCache("/admin-login"))
+		return
+	}
+
+	if !roles.Has(staff.Role, roles.PERM_WORD_FILTERS) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	lists := make([]adminFilterList, 0, len(profanity.LISTS))
+	for _, list := range profanity.LISTS {
+		lists = append(lists, adminFilterList{Name: list, Entries: profanity.List(list)})
+	}
+
+	c.HTML(http.StatusOK, "admin-filters.html", gin.H{
+		"Lists": lists,
+		"Modes": profanity.MODES,
+		"Done":  c.Query("done"),
+		"CSRF":  getStaffFormToken(session),
+	})
+}
+
+func PostAdminFilterAdd(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+
+	if !isStaff || !roles.Has(staff.Role, roles.PERM_WORD_FILTERS) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	list := c.PostForm("list")
+
+	entry, err := profanity.NewEntry(c.PostForm("mode"), c.PostForm("text"))
+	if err == nil {
+		err = profanity.Add(list, entry)
+	}
+
+	if err != nil {
+		redirectToAdminFilters(c, "Couldn't add it: "+err.Error()+".")
+		return
+	}
+
+	audit.Record(audit.Entry{
+		Action: audit.ACTION_FILTER_ADD,
+		Actor:  staff.Nickname,
+		Target: list + " " + entry.String(),
+	})
+
+	redirectToAdminFilters(c, "Added "+entry.String()+" to the "+list+" words.")
+}
+
+func PostAdminFilterRemove(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+
+	if !isStaff || !roles.Has(staff.Role, roles.PERM_WORD_FILTERS) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	list := c.PostForm("list")
+	text := c.PostForm("entry")
+
+	if err := profanity.Remove(list, text); err != nil {
+		redirectToAdminFilters(c, "Couldn't remove it: "+err.Error()+".")
+		return
+	}
+
+	audit.Record(audit.Entry{
+		Action: audit.ACTION_FILTER_REMOVE,
+		Actor:  staff.Nickname,
+		Target: list + " " + text,
+	})
+
+	redirectToAdminFilters(c, "Removed "+text+" from the "+list+" words.")
+}
@@ -0,0 +1,118 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/floodcontrol"
+	"retro-chat-rooms/roles"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+type adminFloodRoom struct {
+	Name string
+	// Whole messages left before it counts as a flood
+	Messages   int
+	FloodCount int
+	Penalty    string
+	LastSeen   string
+}
+
+type adminFloodState struct {
+	IP    string
+	Found bool
+	State floodcontrol.State
+	// Flood ban, it applies to every room
+	Penalty string
+	Rooms   []adminFloodRoom
+}
+
+func describeFloodBan(state floodcontrol.State) string {
+	if now := time.Now(); now.Before(state.BannedUntil) {
+		return "flood banned, " + formatIdleTime(state.BannedUntil.Sub(now)) + " left"
+	}
+	return ""
+}
+
+func describeRoomFlood(room floodcontrol.RoomState) string {
+	if now := time.Now(); now.Before(room.CooldownUntil) {
+		return "flood cooldown, " + formatIdleTime(room.CooldownUntil.Sub(now)) + " left"
+	}
+	if room.FloodCount > 0 {
+		return fmt.Sprintf("flooded %d times", room.FloodCount)
+	}
+	return ""
+}
+
+// describeFloodPenalty says what's stopping the IP from talking in the
+// room, if anything.
+func describeFloodPenalty(state floodcontrol.State, roomId string) string {
+	if ban := describeFloodBan(state); ban != "" {
+		return ban
+	}
+
+	room, found := lo.Find(state.Rooms, func(r floodcontrol.RoomState) bool { return r.RoomID == roomId })
+	if !found {
+		return ""
+	}
+	return describeRoomFlood(room)
+}
+
+func getAdminFloodState(ip string) *adminFloodState {
+	state, found := floodcontrol.GetState(ip)
+
+	result := &adminFloodState{IP: ip, Found: found, State: state}
+	if !found {
+		return result
+	}
+
+	result.Penalty = describeFloodBan(state)
+
+	for _, room := range state.Rooms {
+		name := room.RoomID
+		if chatRoom, found := chat.GetSingleRoom(room.RoomID); found {
+			name = chatRoom.Name
+		}
+
+		result.Rooms = append(result.Rooms, adminFloodRoom{
+			Name:       name,
+			Messages:   int(room.Tokens),
+			FloodCount: room.FloodCount,
+			Penalty:    describeRoomFlood(room),
+			LastSeen:   formatIdleTime(time.Since(room.LastSeen)),
+		})
+	}
+
+	return result
+}
+
+// PostAdminFloodReset lifts flood cooldowns and bans for an IP.
+func PostAdminFloodReset(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+
+	if !isStaff || !roles.Has(staff.Role, roles.PERM_BAN) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	ip := strings.TrimSpace(c.PostForm("ip"))
+	if !floodcontrol.Reset(ip) {
+		redirectToAdmin(c, "Flood control has nothing on "+ip+".")
+		return
+	}
+
+	audit.Record(audit.Entry{
+		Action:   audit.ACTION_FLOOD_RESET,
+		Actor:    staff.Nickname,
+		Target:   ip,
+		TargetIP: ip,
+	})
+
+	redirectToAdmin(c, "Flood control was reset for "+ip+".")
+}
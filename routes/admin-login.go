@@ -1,28 +1,94 @@
 package routes
 
 import (
-	"crypto/sha1"
-	"encoding/hex"
+	"crypto/subtle"
+	"log"
 	"net/http"
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/captcha"
 	"retro-chat-rooms/chat"
 	"retro-chat-rooms/config"
-	"strings"
+	"retro-chat-rooms/roles"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
-func GetAdminLogin(c *gin.Context) {
-	c.HTML(http.StatusOK, "admin-login.html", gin.H{
-		"Rooms": chat.GetAllRooms(),
+// getSessionStaff returns the moderator who logged in with this session.
+func getSessionStaff(session sessions.Session) (config.ModeratorConfig, bool) {
+	token, ok := session.Get("staffSession").(string)
+	if !ok {
+		return config.ModeratorConfig{}, false
+	}
+	return roles.SessionStaff(token)
+}
+
+func getStaffFormToken(session sessions.Session) string {
+	token, _ := session.Get("staffSession").(string)
+	return roles.SessionFormToken(token)
+}
+
+// hasStaffFormToken tells if the form sent back the session's form token,
+// so other sites can't post forms that change something.
+func hasStaffFormToken(c *gin.Context, session sessions.Session) bool {
+	formToken := getStaffFormToken(session)
+	return formToken != "" && subtle.ConstantTimeCompare([]byte(formToken), []byte(c.PostForm("csrf"))) == 1
+}
+
+// getFormStaff is getSessionStaff for forms that change something.
+func getFormStaff(c *gin.Context, session sessions.Session) (config.ModeratorConfig, bool) {
+	if !hasStaffFormToken(c, session) {
+		return config.ModeratorConfig{}, false
+	}
+	return getSessionStaff(session)
+}
+
+// endStaffSession logs the moderator out of the admin area.
+func endStaffSession(session sessions.Session) {
+	if token, ok := session.Get("staffSession").(string); ok {
+		roles.EndSession(token)
+		session.Delete("staffSession")
+	}
+}
+
+func recordFailedLogin(nickname string, roomId string, ip string, err error) {
+	log.Printf("Failed admin login: %q from %s: %v", nickname, ip, err)
+	audit.Record(audit.Entry{
+		Action:   audit.ACTION_LOGIN_FAILED,
+		RoomID:   roomId,
+		Actor:    nickname,
+		TargetIP: ip,
+		Reason:   err.Error(),
 	})
 }
 
+// getAdminLoginData gives the login page its captcha, a new one after
+// every try since each can only be tried once.
+func getAdminLoginData(c *gin.Context, session sessions.Session, errorMessage string) gin.H {
+	sessionUserState := NewSessionUserState(c, session)
+	current, _ := session.Get("adminCaptcha").(string)
+	challenge := captcha.Issue(captcha.ForAdmin(), sessionUserState.GetUserIP(), current)
+	session.Set("adminCaptcha", challenge.ID)
+	if err := session.Save(); err != nil {
+		log.Println("Error saving session:", err)
+	}
+
+	return gin.H{
+		"Rooms":   chat.GetAllRooms(),
+		"Captcha": challenge,
+		"Error":   errorMessage,
+	}
+}
+
+func GetAdminLogin(c *gin.Context, session sessions.Session) {
+	c.HTML(http.StatusOK, "admin-login.html", getAdminLoginData(c, session, ""))
+}
+
 func PostAdminLogin(c *gin.Context, session sessions.Session) {
 	nick := c.PostForm("u")
 	pass := c.PostForm("p")
 	roomId := c.PostForm("r")
-	captcha := c.PostForm("mess")
+	captchaInput := c.PostForm("mess")
 
 	room, found := chat.GetSingleRoom(roomId)
 
@@ -31,35 +97,38 @@ func PostAdminLogin(c *gin.Context, session sessions.Session) {
 		return
 	}
 
-	isOwnerVariation := chat.IsNickVariation(config.Current.OwnerChatUser.Nickname, nick)
+	sessionUserState := NewSessionUserState(c, session)
+	ip := sessionUserState.GetUserIP()
 
-	hasher := sha1.New()
-	hasher.Write([]byte(pass))
-	hash := hex.EncodeToString(hasher.Sum(nil))
+	captchaId, _ := session.Get("adminCaptcha").(string)
 
-	ownerPassword := strings.ToLower(config.Current.OwnerChatUser.Password)
+	if !captcha.Check(captchaId, captchaInput) {
+		c.HTML(http.StatusForbidden, "admin-login.html", getAdminLoginData(c, session, "Solve the captcha to log in."))
+		return
+	}
 
-	sessionCaptcha := session.Get("chaptcha")
+	staff, err := roles.Login(nick, pass, ip)
 
-	captchaInvalid := sessionCaptcha == nil || sessionCaptcha.(string) != captcha
+	if err != nil {
+		recordFailedLogin(nick, roomId, ip, err)
 
-	if !isOwnerVariation || ownerPassword != hash || captchaInvalid {
-		c.HTML(http.StatusForbidden, "admin-login.html", gin.H{
-			"Rooms": chat.GetAllRooms(),
-		})
+		c.HTML(http.StatusForbidden, "admin-login.html", getAdminLoginData(c, session, roles.DescribeLoginError(nick, ip, err)))
 		return
 	}
 
-	registeredUserId := chat.RegisterAdmin(roomId, userAgentToClientInfo(c.GetHeader("User-Agent")))
+	roles.SignIn(staff, roomId, userAgentToClientInfo(c.GetHeader("User-Agent")), ip)
 
-	userId := session.Get("userId")
-	combinedId := chat.GetCombinedId(roomId, userId.(string))
-
-	if userId == nil || combinedId != registeredUserId {
-		session.Set("userId", config.Current.OwnerChatUser.Id)
-		session.Save()
-	}
+	log.Printf("Admin login: %s from %s", staff.Nickname, ip)
+	audit.Record(audit.Entry{
+		Action:   audit.ACTION_LOGIN,
+		RoomID:   roomId,
+		Actor:    staff.Nickname,
+		TargetIP: ip,
+	})
 
+	session.Set("userId", roles.StaffUserId(staff))
+	endStaffSession(session)
+	session.Set("staffSession", roles.StartSession(staff))
 	session.Set("supportsChatEventAwaiter", supportsChatEventAwaiter(c))
 	session.Save()
 
@@ -0,0 +1,189 @@
+package routes
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/bans"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/moderation"
+	"retro-chat-rooms/reports"
+	"retro-chat-rooms/roles"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+type adminReportRow struct {
+	Report   reports.Report
+	RoomName string
+	Online   bool
+	Actions  []adminAction
+}
+
+const REPORT_ACTION_DISMISS = "dismiss"
+
+// getReportActions lists what the moderator can do about a report, the
+// author can still be banned by IP after leaving.
+func getReportActions(staff config.ModeratorConfig, r reports.Report) []adminAction {
+	actions := []adminAction{{REPORT_ACTION_DISMISS, "Dismiss"}}
+
+	if author, online := chat.GetUser(r.AuthorID); online {
+		for _, a := range getAdminActions(staff, author) {
+			if lo.Contains([]string{"kick", "ban", "shadow", "mute", "purge"}, a.Value) {
+				actions = append(actions, a)
+			}
+		}
+	} else if r.AuthorIP != "" && roles.Has(roles.RoleIn(staff, r.RoomID), roles.PERM_BAN) {
+		actions = append(actions, adminAction{"ban", fmt.Sprintf("Ban IP (%d min)", moderation.DEFAULT_BAN_MIN)})
+	}
+
+	return actions
+}
+
+func canSeeReport(staff config.ModeratorConfig, r reports.Report) bool {
+	return roles.Has(roles.RoleIn(staff, r.RoomID), roles.PERM_KICK)
+}
+
+func redirectToReports(c *gin.Context, done string) {
+	c.Redirect(http.StatusFound, "/admin/reports?done="+url.QueryEscape(done))
+}
+
+// GetAdminReports shows the open reports, or all of them with ?all=1.
+func GetAdminReports(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getSessionStaff(session)
+
+	if !isStaff {
+		c.Redirect(http.StatusFound, BustCache("/admin-login"))
+		return
+	}
+
+	all := c.Query("all") != ""
+	rows := make([]adminReportRow, 0)
+
+	for _, r := range reports.List(all) {
+		if !canSeeReport(staff, r) {
+			continue
+		}
+
+		room, _ := chat.GetSingleRoom(r.RoomID)
+		_, online := chat.GetUser(r.AuthorID)
+
+		row := adminReportRow{
+			Report:   r,
+			RoomName: room.Name,
+			Online:   online,
+		}
+
+		if r.Status == reports.STATUS_OPEN {
+			row.Actions = getReportActions(staff, r)
+		}
+
+		rows = append(rows, row)
+	}
+
+	c.HTML(http.StatusOK, "admin-reports.html", gin.H{
+		"Reports": rows,
+		"All":     all,
+		"Done":    c.Query("done"),
+		"CSRF":    getStaffFormToken(session),
+	})
+}
+
+func PostAdminReportResolve(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+
+	if !isStaff {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	report, found := reports.Get(c.PostForm("id"))
+
+	if !found || !canSeeReport(staff, report) {
+		redirectToReports(c, "That report doesn't exist anymore.")
+		return
+	}
+
+	action := c.PostForm("action")
+
+	allowed := lo.ContainsBy(getReportActions(staff, report), func(a adminAction) bool {
+		return a.Value == action
+	})
+
+	if !allowed {
+		redirectToReports(c, "You are not allowed to do that.")
+		return
+	}
+
+	actor := template.HTMLEscapeString(staff.Nickname)
+	reason := c.PostForm("reason")
+	if reason == "" {
+		reason = report.Reason
+	}
+
+	status := reports.STATUS_ACTIONED
+	done := ""
+	author, online := chat.GetUser(report.AuthorID)
+
+	switch {
+	case action == REPORT_ACTION_DISMISS:
+		status = reports.STATUS_DISMISSED
+		done = "The report was dismissed."
+	case action == "ban" && !online:
+		_, err := bans.Add(bans.TYPE_IP, report.AuthorIP, reason, staff.Nickname, moderation.DEFAULT_BAN_MIN*time.Minute)
+		if err != nil {
+			redirectToReports(c, "Couldn't add the ban: "+err.Error()+".")
+			return
+		}
+
+		audit.Record(audit.Entry{
+			Action: audit.ACTION_BAN_ADD,
+			RoomID: report.RoomID,
+			Actor:  staff.Nickname,
+			Target: report.Author,
+			Reason: reason,
+		})
+		done = report.Author + "'s IP was banned."
+	case action == "kick":
+		moderation.Kick(actor, author, reason)
+		done = report.Author + " was kicked."
+	case action == "ban":
+		moderation.Ban(actor, author, reason, moderation.DEFAULT_BAN_MIN*time.Minute)
+		done = fmt.Sprintf("%s was banned for %d minutes.", report.Author, moderation.DEFAULT_BAN_MIN)
+	case action == "shadow":
+		moderation.Shadow(actor, author, reason, 0)
+		done = report.Author + " is shadow banned."
+	case action == "mute":
+		moderation.Mute(actor, author, reason, moderation.DEFAULT_MUTE_MIN*time.Minute)
+		done = fmt.Sprintf("%s was muted for %d minutes.", report.Author, moderation.DEFAULT_MUTE_MIN)
+	case action == "purge":
+		purged := moderation.Purge(actor, author, reason)
+		done = fmt.Sprintf("%d messages from %s were removed.", purged, report.Author)
+	default:
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if _, err := reports.Resolve(report.ID, status, staff.Nickname, action); err != nil {
+		redirectToReports(c, "Couldn't close the report: "+err.Error()+".")
+		return
+	}
+
+	if status == reports.STATUS_DISMISSED {
+		audit.Record(audit.Entry{
+			Action: audit.ACTION_REPORT_DISMISS,
+			RoomID: report.RoomID,
+			Actor:  staff.Nickname,
+			Target: report.Author,
+			Reason: report.Reason,
+		})
+	}
+
+	redirectToReports(c, done)
+}
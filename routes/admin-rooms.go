@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"html/template"
+	"net/http"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/moderation"
+	"retro-chat-rooms/roles"
+	"strconv"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func PostAdminRoom(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+	room, found := chat.GetSingleRoom(c.PostForm("id"))
+
+	if !isStaff || !found || !roles.Has(roles.RoleIn(staff, room.ID), roles.PERM_ROOM_SETTINGS) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	seconds, err := strconv.Atoi(c.PostForm("slow"))
+	if err != nil || seconds < 0 || seconds > chat.MAX_SLOW_MODE_SEC {
+		redirectToAdmin(c, "Slow mode takes between 0 and "+strconv.Itoa(chat.MAX_SLOW_MODE_SEC)+" seconds.")
+		return
+	}
+
+	if seconds == room.SlowModeSec {
+		redirectToAdmin(c, "Nothing changed in "+room.Name+".")
+		return
+	}
+
+	moderation.SetSlowMode(template.HTMLEscapeString(staff.Nickname), room.ID, seconds)
+
+	if seconds == 0 {
+		redirectToAdmin(c, "Slow mode is off in "+room.Name+".")
+		return
+	}
+
+	redirectToAdmin(c, "Slow mode is on in "+room.Name+".")
+}
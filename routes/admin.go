@@ -0,0 +1,291 @@
+package routes
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"retro-chat-rooms/bans"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/floodcontrol"
+	"retro-chat-rooms/moderation"
+	"retro-chat-rooms/reports"
+	"retro-chat-rooms/roles"
+	"retro-chat-rooms/spam"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+type adminAction struct {
+	Value string
+	Label string
+}
+
+type adminUserRow struct {
+	User     chat.ChatUser
+	RoomName string
+	Badge    string
+	Idle     string
+	MutedFor string
+	// Spam strikes within the spam window
+	SpamScore int
+	// Flood control cooldowns and bans on their IP
+	Flood string
+	// IPs are only for those who can ban in the room
+	ShowIP  bool
+	Actions []adminAction
+}
+
+const ROLE_ACTION_PREFIX = "role:"
+
+func formatIdleTime(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// getAdminActions lists what the moderator is allowed to do to the target.
+func getAdminActions(staff config.ModeratorConfig, target chat.ChatUser) []adminAction {
+	role := roles.RoleIn(staff, target.RoomId)
+	actions := make([]adminAction, 0)
+
+	if roles.CanModerate(role, target, roles.PERM_KICK) {
+		actions = append(actions, adminAction{"kick", "Kick"})
+	}
+	if roles.CanModerate(role, target, roles.PERM_BAN) {
+		actions = append(actions, adminAction{"ban", fmt.Sprintf("Ban (%d min)", moderation.DEFAULT_BAN_MIN)})
+
+		if target.Shadowed {
+			actions = append(actions, adminAction{"unshadow", "Lift shadow ban"})
+		} else {
+			actions = append(actions, adminAction{"shadow", "Shadow ban"})
+		}
+	}
+	if roles.CanModerate(role, target, roles.PERM_MUTE) {
+		actions = append(actions, adminAction{"mute", fmt.Sprintf("Mute (%d min)", moderation.DEFAULT_MUTE_MIN)})
+
+		if chat.IsUserMuted(target) {
+			actions = append(actions, adminAction{"unmute", "Unmute"})
+		}
+	}
+	if roles.CanModerate(role, target, roles.PERM_PURGE) {
+		actions = append(actions, adminAction{"purge", "Purge messages"})
+	}
+	if roles.CanModerate(role, target, roles.PERM_ASSIGN_ROLES) {
+		for _, r := range []string{chat.ROLE_VOICED, chat.ROLE_ROOM_OPERATOR, chat.ROLE_GLOBAL_MODERATOR} {
+			if roles.Outranks(role, r) && r != target.Role {
+				actions = append(actions, adminAction{ROLE_ACTION_PREFIX + r, "Make " + roles.Badge(r)})
+			}
+		}
+		if target.Role != "" {
+			actions = append(actions, adminAction{ROLE_ACTION_PREFIX, "Remove role"})
+		}
+	}
+
+	return actions
+}
+
+// canUseConsole tells if the moderator may see the users of the room.
+func canUseConsole(staff config.ModeratorConfig, roomId string) bool {
+	return roles.Has(roles.RoleIn(staff, roomId), roles.PERM_CONSOLE)
+}
+
+func GetAdmin(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getSessionStaff(session)
+
+	if !isStaff {
+		c.Redirect(http.StatusFound, BustCache("/admin-login"))
+		return
+	}
+
+	if !lo.SomeBy(chat.GetAllRooms(), func(room chat.ChatRoom) bool { return canUseConsole(staff, room.ID) }) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	users := lo.Filter(chat.GetAllUsers(), func(user chat.ChatUser, _ int) bool {
+		return canUseConsole(staff, user.RoomId)
+	})
+
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].RoomId != users[j].RoomId {
+			return users[i].RoomId < users[j].RoomId
+		}
+		return users[i].Nickname < users[j].Nickname
+	})
+
+	rows := make([]adminUserRow, 0, len(users))
+
+	for _, user := range users {
+		room, _ := chat.GetSingleRoom(user.RoomId)
+
+		row := adminUserRow{
+			User:      user,
+			RoomName:  room.Name,
+			Badge:     roles.Badge(user.Role),
+			Idle:      formatIdleTime(chat.GetUserIdleTime(user.ID)),
+			Actions:   getAdminActions(staff, user),
+			SpamScore: chat.GetSpamScore(user),
+			ShowIP:    roles.Has(roles.RoleIn(staff, user.RoomId), roles.PERM_BAN),
+		}
+
+		if end, muted := chat.GetMuteEnd(user); muted {
+			row.MutedFor = formatIdleTime(time.Until(end))
+		}
+
+		if state, found := floodcontrol.GetState(user.IP); found && user.IP != "" && row.ShowIP {
+			row.Flood = describeFloodPenalty(state, user.RoomId)
+		}
+
+		rows = append(rows, row)
+	}
+
+	exportRooms := make([]chat.ChatRoom, 0)
+	settingsRooms := make([]chat.ChatRoom, 0)
+	for _, room := range chat.GetAllRooms() {
+		if roles.Has(roles.RoleIn(staff, room.ID), roles.PERM_EXPORT) {
+			exportRooms = append(exportRooms, room)
+		}
+		if roles.Has(roles.RoleIn(staff, room.ID), roles.PERM_ROOM_SETTINGS) {
+			settingsRooms = append(settingsRooms, room)
+		}
+	}
+
+	canBan := roles.Has(staff.Role, roles.PERM_BAN)
+	banList := make([]bans.Ban, 0)
+	if canBan {
+		banList = bans.List()
+	}
+
+	openReports := lo.CountBy(reports.List(false), func(r reports.Report) bool {
+		return canSeeReport(staff, r)
+	})
+
+	spamReasons, spamActions := spam.Counters()
+
+	floodIP := strings.TrimSpace(c.Query("flood"))
+	var floodState *adminFloodState
+	if canBan && floodIP != "" {
+		floodState = getAdminFloodState(floodIP)
+	}
+
+	c.HTML(http.StatusOK, "admin.html", gin.H{
+		"Nickname":       staff.Nickname,
+		"OpenReports":    openReports,
+		"Badge":          roles.Badge(staff.Role),
+		"Users":          rows,
+		"Rooms":          exportRooms,
+		"SettingsRooms":  settingsRooms,
+		"SpamEnabled":    spam.IsEnabled(),
+		"SpamReasons":    spamReasons,
+		"SpamActions":    spamActions,
+		"Done":           c.Query("done"),
+		"CSRF":           getStaffFormToken(session),
+		"CanBan":         canBan,
+		"CanEditFilters": roles.Has(staff.Role, roles.PERM_WORD_FILTERS),
+		"Bans":           banList,
+		"BanTypes":       bans.TYPES,
+		"FloodIP":        floodIP,
+		"FloodState":     floodState,
+	})
+}
+
+func redirectToAdmin(c *gin.Context, done string) {
+	c.Redirect(http.StatusFound, "/admin?done="+url.QueryEscape(done))
+}
+
+func PostAdminAction(c *gin.Context, session sessions.Session) {
+	staff, isStaff := getFormStaff(c, session)
+
+	if !isStaff {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	target, found := chat.GetUser(c.PostForm("u"))
+
+	if !found {
+		redirectToAdmin(c, "That user is not online anymore.")
+		return
+	}
+
+	action := c.PostForm("action")
+
+	allowed := lo.ContainsBy(getAdminActions(staff, target), func(a adminAction) bool {
+		return a.Value == action
+	})
+
+	if !allowed {
+		redirectToAdmin(c, "You are not allowed to do that to "+target.Nickname+".")
+		return
+	}
+
+	actor := template.HTMLEscapeString(staff.Nickname)
+	reason := c.PostForm("reason")
+
+	minutes, err := strconv.Atoi(c.PostForm("min"))
+	if err != nil || minutes < 1 {
+		minutes = 0
+	}
+	if minutes > moderation.MAX_BAN_MIN {
+		minutes = moderation.MAX_BAN_MIN
+	}
+
+	done := ""
+
+	switch {
+	case action == "kick":
+		moderation.Kick(actor, target, reason)
+		done = target.Nickname + " was kicked."
+	case action == "ban":
+		if minutes == 0 {
+			minutes = moderation.DEFAULT_BAN_MIN
+		}
+		moderation.Ban(actor, target, reason, time.Duration(minutes)*time.Minute)
+		done = fmt.Sprintf("%s was banned for %d minutes.", target.Nickname, minutes)
+	case action == "mute":
+		if minutes == 0 {
+			minutes = moderation.DEFAULT_MUTE_MIN
+		}
+		moderation.Mute(actor, target, reason, time.Duration(minutes)*time.Minute)
+		done = fmt.Sprintf("%s was muted for %d minutes.", target.Nickname, minutes)
+	case action == "unmute":
+		moderation.Unmute(actor, target)
+		done = target.Nickname + " was unmuted."
+	case action == "shadow":
+		// Without minutes it lasts until lifted
+		moderation.Shadow(actor, target, reason, time.Duration(minutes)*time.Minute)
+		done = target.Nickname + " is shadow banned."
+	case action == "unshadow":
+		moderation.Unshadow(actor, target)
+		done = target.Nickname + " is not shadow banned anymore."
+	case action == "purge":
+		purged := moderation.Purge(actor, target, reason)
+		done = fmt.Sprintf("%d messages from %s were removed.", purged, target.Nickname)
+	case strings.HasPrefix(action, ROLE_ACTION_PREFIX):
+		role := strings.TrimPrefix(action, ROLE_ACTION_PREFIX)
+		if err := roles.Assign(actor, roles.RoleIn(staff, target.RoomId), target, role); err != nil {
+			done = "Couldn't change the role: " + err.Error() + "."
+		} else if role == "" {
+			done = target.Nickname + " has no role anymore."
+		} else {
+			done = target.Nickname + " is now " + roles.Badge(role) + "."
+		}
+	default:
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	redirectToAdmin(c, done)
+}
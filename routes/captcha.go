@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/captcha"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetCaptcha(c *gin.Context) {
+	data, found := captcha.Image(c.Param("id"))
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache, no-store")
+	c.Header("Pragma", "no-cache")
+	c.Header("Expires", "0")
+	c.Data(http.StatusOK, "image/gif", data)
+}
@@ -23,5 +23,6 @@ func GetChatHeader(c *gin.Context) {
 		"Color":     room.Color,
 		"TextColor": room.TextColor,
 		"Logo":      config.Current.ChatRoomHeaderLogo,
+		"SlowMode":  room.SlowModeSec,
 	})
 }
@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/moderation"
+	"retro-chat-rooms/roles"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// getModerationUsers returns who is moderating and who they target,
+// ok is false if they are not allowed to kick them.
+func getModerationUsers(c *gin.Context, session sessions.Session, to string) (chat.ChatUser, chat.ChatUser, bool) {
+	user, found := getSessionChatUser(session, c.Param("id"))
+	if !found {
+		return chat.ChatUser{}, chat.ChatUser{}, false
+	}
+
+	target, found := chat.GetUser(to)
+	if !found {
+		return user, chat.ChatUser{}, false
+	}
+
+	return user, target, roles.CanModerate(roles.RoleFor(user, target.RoomId), target, roles.PERM_KICK)
+}
+
+// GetChatModerate shows the kick/ban form in the talk frame.
+func GetChatModerate(c *gin.Context, session sessions.Session) {
+	room, found := chat.GetSingleRoom(c.Param("id"))
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	user, target, ok := getModerationUsers(c, session, c.Query("to"))
+	if !ok {
+		c.Redirect(http.StatusFound, UrlChatTalk(room.ID, ""))
+		return
+	}
+
+	c.HTML(http.StatusOK, "chat-moderate.html", gin.H{
+		"ID":            room.ID,
+		"Color":         room.Color,
+		"TextColor":     room.TextColor,
+		"To":            target,
+		"CanBan":        roles.CanModerate(roles.RoleFor(user, target.RoomId), target, roles.PERM_BAN),
+		"DefaultBanMin": moderation.DEFAULT_BAN_MIN,
+		"MaxBanMin":     moderation.MAX_BAN_MIN,
+		"CSRF":          getStaffFormToken(session),
+	})
+}
+
+func PostChatModerate(c *gin.Context, session sessions.Session) {
+	roomId := c.Param("id")
+
+	if !hasStaffFormToken(c, session) {
+		c.String(http.StatusForbidden, "Moderators only.")
+		return
+	}
+
+	user, target, ok := getModerationUsers(c, session, c.PostForm("to"))
+	if !ok {
+		c.Redirect(http.StatusFound, UrlChatTalk(roomId, ""))
+		return
+	}
+
+	reason := c.PostForm("reason")
+
+	if c.PostForm("ban") != "" && roles.CanModerate(roles.RoleFor(user, target.RoomId), target, roles.PERM_BAN) {
+		minutes, err := strconv.Atoi(c.PostForm("min"))
+		if err != nil || minutes < 1 || minutes > moderation.MAX_BAN_MIN {
+			minutes = moderation.DEFAULT_BAN_MIN
+		}
+
+		moderation.Ban(user.Nickname, target, reason, time.Duration(minutes)*time.Minute)
+	} else {
+		moderation.Kick(user.Nickname, target, reason)
+	}
+
+	c.Redirect(http.StatusFound, UrlChatTalk(roomId, ""))
+}
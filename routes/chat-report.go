@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/reports"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func renderChatReport(c *gin.Context, room chat.ChatRoom, message *chat.ChatMessage, err error) {
+	data := gin.H{
+		"ID":        room.ID,
+		"Color":     room.Color,
+		"TextColor": room.TextColor,
+		"MessageID": message.ID,
+		"Message":   message.Message,
+		"MaxLength": reports.MAX_REASON_LENGTH,
+	}
+
+	if from := message.GetFrom(); from != nil {
+		data["Nickname"] = from.Nickname
+	}
+
+	if err != nil {
+		data["Error"] = err.Error()
+	}
+
+	c.HTML(http.StatusOK, "chat-report.html", data)
+}
+
+// GetChatReport shows the report form in the talk frame.
+func GetChatReport(c *gin.Context, session sessions.Session) {
+	room, found := chat.GetSingleRoom(c.Param("id"))
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	user, found := getSessionChatUser(session, room.ID)
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	message, found := chat.FindUserMessage(user.ID, c.Query("m"))
+	if !found || message.IsSystemMessage || message.From == user.ID {
+		c.Redirect(http.StatusFound, UrlChatTalk(room.ID, ""))
+		return
+	}
+
+	renderChatReport(c, room, message, nil)
+}
+
+func PostChatReport(c *gin.Context, session sessions.Session) {
+	room, found := chat.GetSingleRoom(c.Param("id"))
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	user, found := getSessionChatUser(session, room.ID)
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	userState := NewSessionUserState(c, session)
+
+	_, err := reports.Submit(user, userState.GetUserIP(), c.PostForm("m"), c.PostForm("reason"))
+
+	if err == reports.ErrReasonTooLong {
+		if message, found := chat.FindUserMessage(user.ID, c.PostForm("m")); found {
+			renderChatReport(c, room, message, err)
+			return
+		}
+	}
+
+	if err != nil {
+		chat.SendSystemNotice(user, "Sorry {nickname}, "+err.Error()+".")
+	} else {
+		chat.SendSystemNotice(user, "Thanks {nickname}, a moderator will look at your report.")
+	}
+
+	c.Redirect(http.StatusFound, UrlChatTalk(room.ID, ""))
+}
@@ -3,13 +3,14 @@ package routes
 import (
 	"net/http"
 	"retro-chat-rooms/chat"
+	"retro-chat-rooms/commands"
 	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
-func sendHtml(ctx *gin.Context, room chat.ChatRoom, user chat.ChatUser, toUserId string, updateUpdater bool, private bool) {
+func sendHtml(ctx *gin.Context, room chat.ChatRoom, user chat.ChatUser, toUserId string, replyId string, updateUpdater bool, private bool) {
 	var to chat.ChatUser
 	if toUserId != "" {
 		toUser, fnd := chat.GetUser(toUserId)
@@ -18,6 +19,13 @@ func sendHtml(ctx *gin.Context, room chat.ChatRoom, user chat.ChatUser, toUserId
 		}
 	}
 
+	var replyTo *chat.QuotedMessage
+	if replyId != "" {
+		if message, fnd := chat.FindUserMessage(user.ID, replyId); fnd {
+			replyTo = chat.QuoteMessage(message)
+		}
+	}
+
 	ctx.HTML(http.StatusOK, "chat-talk.html", gin.H{
 		"ID":            room.ID,
 		"UserId":        user.ID,
@@ -25,9 +33,10 @@ func sendHtml(ctx *gin.Context, room chat.ChatRoom, user chat.ChatUser, toUserId
 		"To":            to,
 		"Color":         room.Color,
 		"TextColor":     room.TextColor,
-		"SpeechModes":   chat.SPEECH_MODES,
+		"SpeechModes":   chat.GetSpeechModes(room.ID),
 		"UpdateUpdater": updateUpdater,
 		"Private":       private,
+		"ReplyTo":       replyTo,
 	})
 }
 
@@ -44,6 +53,7 @@ func getSupportsChatEventAwaiter(session sessions.Session) bool {
 func GetChatTalk(c *gin.Context, session sessions.Session) {
 	roomId := c.Param("id")
 	toUserId := c.Query("to")
+	replyId := c.Query("reply")
 
 	room, found := chat.GetSingleRoom(roomId)
 
@@ -62,7 +72,7 @@ func GetChatTalk(c *gin.Context, session sessions.Session) {
 		return
 	}
 
-	sendHtml(c, room, user, toUserId, false, false)
+	sendHtml(c, room, user, toUserId, replyId, false, false)
 }
 
 func PostChatTalk(c *gin.Context, session sessions.Session) {
@@ -71,6 +81,7 @@ func PostChatTalk(c *gin.Context, session sessions.Session) {
 	mode := c.PostForm("speechMode")
 	private := c.PostForm("private")
 	toUserId := c.PostForm("to")
+	replyId := c.PostForm("reply")
 
 	sessionUserState := NewSessionUserState(c, session)
 	// TODO: Figure out why this needs ! and write a comment
@@ -93,6 +104,14 @@ func PostChatTalk(c *gin.Context, session sessions.Session) {
 		return
 	}
 
+	if commands.IsCommand(message) {
+		ctx := commands.NewContext(user, sessionUserState.GetUserIP())
+		ctx.ReplyTo = replyId
+		commands.Execute(ctx, message)
+		sendHtml(c, room, user, toUserId, "", updateUpdater, private == "on")
+		return
+	}
+
 	involvedUsers := []chat.ChatUser{user}
 	toUser, foundToUser := chat.GetUser(toUserId)
 
@@ -111,14 +130,15 @@ func PostChatTalk(c *gin.Context, session sessions.Session) {
 		IsSystemMessage: false,
 		Source:          chat.ClientInfoToMsgSource(user.Client),
 		InvolvedUsers:   involvedUsers,
+		ReplyTo:         &chat.QuotedMessage{ID: replyId},
 	})
 
 	if !canSend {
-		sendHtml(c, room, user, toUserId, false, false)
+		sendHtml(c, room, user, toUserId, replyId, false, false)
 		return
 	}
 
 	chat.SendMessage(&finalMessage)
 
-	sendHtml(c, room, user, toUserId, updateUpdater, private == "on")
+	sendHtml(c, room, user, toUserId, "", updateUpdater, private == "on")
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"net/http"
 	"retro-chat-rooms/chat"
+	"retro-chat-rooms/polls"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -21,8 +22,15 @@ func GetChatThread(c *gin.Context, session sessions.Session) {
 		return
 	}
 
+	poll, hasPoll := polls.GetActivePoll(roomId)
+	sessionUserState := NewSessionUserState(c, session)
+
 	c.HTML(http.StatusOK, "chat-thread.html", gin.H{
+		"ID":       roomId,
 		"UserID":   combinedId,
 		"Messages": messages,
+		"HasPoll":  hasPoll,
+		"Poll":     poll,
+		"CSRF":     sessionUserState.GetFormToken(),
 	})
 }
@@ -18,8 +18,18 @@ func waitForChatEvent(roomId string, combinedId string, cb func(messageUpdates b
 			cb(false, true)
 		case chat.ChatUserLeftEvent:
 			cb(false, true)
+		case chat.ChatUserUpdatedEvent:
+			cb(false, true)
 		case chat.ChatMessageEvent:
 			cb(true, false)
+		case chat.ChatMessagesPurgedEvent:
+			cb(true, false)
+		case chat.ChatRoomUpdatedEvent:
+			cb(false, false)
+		case chat.DirectMessageEvent:
+			cb(false, false)
+		default:
+			cb(false, false)
 		}
 
 		break
@@ -33,21 +43,29 @@ func getData(room chat.ChatRoom, combinedId string, hasMessages bool, userListUp
 	_, found := chat.GetUser(combinedId)
 
 	if !found {
+		_, kicked := chat.GetKickNotice(combinedId)
+
 		return gin.H{
 			"UserGone": true,
+			"Kicked":   kicked,
 			"ID":       room.ID,
 		}, true
 	}
 
 	chat.Ping(combinedId)
 
+	hasDirectMessages := chat.HasUnreadDirectMessages(combinedId)
+	headerUpdated := chat.HasRoomSettingsChanged(combinedId)
+
 	return gin.H{
 		"ID":                       room.ID,
 		"HasMessages":              hasMessages,
 		"UserListUpdated":          userListUpdated,
 		"Color":                    room.Color,
 		"SupportsChatEventAwaiter": supportsAwaiter,
-	}, !supportsAwaiter || hasMessages || userListUpdated
+		"HasDirectMessages":        hasDirectMessages,
+		"HeaderUpdated":            headerUpdated,
+	}, !supportsAwaiter || hasMessages || userListUpdated || hasDirectMessages || headerUpdated
 }
 
 func GetChatUpdater(c *gin.Context, session sessions.Session) {
@@ -58,7 +76,19 @@ func GetChatUpdater(c *gin.Context, session sessions.Session) {
 
 	user, hasUser := chat.GetUser(combinedId)
 
-	if !found || !hasUser {
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	// Kicked users get one last update that sends them to the reason
+	if !hasUser {
+		if _, kicked := chat.GetKickNotice(combinedId); kicked {
+			data, _ := getData(room, combinedId, false, false, false)
+			c.HTML(http.StatusOK, "chat-updater.html", data)
+			return
+		}
+
 		c.Status(http.StatusNotFound)
 		return
 	}
@@ -76,7 +106,14 @@ func GetChatUpdater(c *gin.Context, session sessions.Session) {
 			ShowClientIcon:       false,
 		})
 
-		chat.DeregisterUser(combinedId)
+		chat.KickUser(combinedId, "You have been temporarily kicked out for flooding.")
+
+		chat.PublishModerationEvent(chat.ChatModerationEvent{
+			Action: chat.MODERATION_FLOOD_KICK,
+			RoomID: roomId,
+			Target: user,
+			Reason: "Flooding the channel",
+		})
 
 		data, _ := getData(room, combinedId, false, false, false)
 
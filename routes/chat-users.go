@@ -3,6 +3,7 @@ package routes
 import (
 	"net/http"
 	"retro-chat-rooms/chat"
+	"retro-chat-rooms/roles"
 	"time"
 
 	"github.com/gin-contrib/sessions"
@@ -30,10 +31,17 @@ func GetChatUsers(c *gin.Context, session sessions.Session) {
 
 	onlineUsers := chat.GetRoomOnlineUsers(roomId)
 
+	// Who the user can kick, moderators get a link next to them
+	canKick := map[string]bool{}
+	for _, u := range onlineUsers {
+		canKick[u.ID] = roles.CanModerate(roles.RoleFor(user, u.RoomId), u, roles.PERM_KICK)
+	}
+
 	c.HTML(http.StatusOK, "chat-users.html", gin.H{
 		"ID":          room.ID,
 		"UserID":      user.ID,
 		"Users":       onlineUsers,
+		"CanKick":     canKick,
 		"UsersOnline": len(onlineUsers),
 		"RoomTime":    time.Now().UTC(),
 	})
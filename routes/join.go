@@ -5,6 +5,8 @@ import (
 	"log"
 	"math/rand"
 	"net/http"
+	"retro-chat-rooms/bans"
+	"retro-chat-rooms/captcha"
 	"retro-chat-rooms/chat"
 	"strconv"
 	"strings"
@@ -34,20 +36,6 @@ func generateFieldNames() map[string]string {
 	return fieldMap
 }
 
-func getWestCoastDayOfMonth() int {
-	loc, err := time.LoadLocation("America/Los_Angeles")
-	if err != nil {
-		log.Printf("Error loading timezone: %v", err)
-		return time.Now().Day()
-	}
-	return time.Now().In(loc).Day()
-}
-
-func generateCaptcha() (int, int) {
-	a := rand.Intn(8999) + 1000
-	return a, a + getWestCoastDayOfMonth()
-}
-
 func userAgentToClientInfo(userAgent string) chat.ClientInfo {
 	parser, err := uaparser.New("./ua_regexes.yaml")
 	if err != nil {
@@ -99,14 +87,16 @@ func supportsChatEventAwaiter(c *gin.Context) bool {
 	return true
 }
 
-func getJoinData(session sessions.Session, room chat.ChatRoom, postUrl string, errors []string) *gin.H {
+func getJoinData(c *gin.Context, session sessions.Session, room chat.ChatRoom, postUrl string, errors []string) *gin.H {
 	fieldNames := generateFieldNames()
 	session.Set("fieldNames", fieldNames)
 	if err := session.Save(); err != nil {
 		log.Println("Error saving session:", err)
 	}
-	a, sum := generateCaptcha()
-	session.Set("captcha", sum)
+	sessionUserState := NewSessionUserState(c, session)
+	current, _ := session.Get("captcha").(string)
+	challenge := captcha.Issue(captcha.ForRoom(room.ID), sessionUserState.GetUserIP(), current)
+	session.Set("captcha", challenge.ID)
 	if err := session.Save(); err != nil {
 		log.Println("Error saving session:", err)
 	}
@@ -121,7 +111,7 @@ func getJoinData(session sessions.Session, room chat.ChatRoom, postUrl string, e
 		"Name":        room.Name,
 		"PostUrl":     postUrl,
 		"FieldNames":  fieldNames,
-		"CaptchaA":    a,
+		"Captcha":     challenge,
 	}
 }
 
@@ -132,19 +122,19 @@ func GetJoin(c *gin.Context, session sessions.Session) {
 		c.Status(http.StatusNotFound)
 		return
 	}
-	c.HTML(http.StatusOK, "join.html", getJoinData(session, room, UrlJoin(roomId), make([]string, 0)))
+	c.HTML(http.StatusOK, "join.html", getJoinData(c, session, room, UrlJoin(roomId), make([]string, 0)))
 }
 
 func validateAndJoin(c *gin.Context, session sessions.Session, room chat.ChatRoom, urlPost string) *gin.H {
 	fieldNamesInterface := session.Get("fieldNames")
 	if fieldNamesInterface == nil {
-		return getJoinData(session, room, urlPost, []string{"Session expired, please try again."})
+		return getJoinData(c, session, room, urlPost, []string{"Session expired, please try again."})
 	}
 
 	fieldNames, ok := fieldNamesInterface.(map[string]string)
 	if !ok {
 		log.Println("Error: fieldNames is not of expected type")
-		return getJoinData(session, room, urlPost, []string{"An error occurred, please try again."})
+		return getJoinData(c, session, room, urlPost, []string{"An error occurred, please try again."})
 	}
 
 	nickname := c.PostForm(fieldNames["nickname"])
@@ -156,23 +146,25 @@ func validateAndJoin(c *gin.Context, session sessions.Session, room chat.ChatRoo
 		session.Set("userId", userId)
 		session.Save()
 	}
-	sessionCaptcha := session.Get("captcha")
+	captchaId, _ := session.Get("captcha").(string)
 	errors := make([]string, 0)
 
-	if sessionCaptcha == nil || strconv.Itoa(sessionCaptcha.(int)) != captchaInput {
+	if !captcha.Check(captchaId, captchaInput) {
 		errors = append(errors, "The entered captcha is invalid.")
 	}
 
 	sessionUserState := NewSessionUserState(c, session)
 
 	newUser := chat.ChatUser{
-		RoomId:    room.ID,
-		ID:        chat.GetCombinedId(room.ID, userId.(string)),
-		Nickname:  nickname,
-		Color:     color,
-		DiscordId: "",
-		IsAdmin:   false,
-		Client:    userAgentToClientInfo(c.GetHeader("User-Agent")),
+		RoomId:      room.ID,
+		ID:          chat.GetCombinedId(room.ID, userId.(string)),
+		Nickname:    nickname,
+		Color:       color,
+		DiscordId:   "",
+		IsAdmin:     false,
+		Client:      userAgentToClientInfo(c.GetHeader("User-Agent")),
+		IP:          sessionUserState.GetUserIP(),
+		Fingerprint: bans.Fingerprint(userId.(string)),
 	}
 
 	chat.ValidateUser(&sessionUserState, newUser, &errors)
@@ -187,7 +179,7 @@ func validateAndJoin(c *gin.Context, session sessions.Session, room chat.ChatRoo
 		}
 	}
 	if len(errors) > 0 {
-		return getJoinData(session, room, urlPost, errors)
+		return getJoinData(c, session, room, urlPost, errors)
 	}
 
 	session.Set("supportsChatEventAwaiter", supportsChatEventAwaiter(c))
@@ -207,6 +199,23 @@ func PostJoin(c *gin.Context, session sessions.Session) {
 		return
 	}
 
+	sessionUserState := NewSessionUserState(c, session)
+	candidate := bans.Candidate{IP: sessionUserState.GetUserIP()}
+	if userId, ok := session.Get("userId").(string); ok {
+		candidate.Fingerprint = bans.Fingerprint(userId)
+	}
+
+	if ban, banned := bans.Check(candidate); banned {
+		c.HTML(http.StatusForbidden, "kicked.html", gin.H{
+			"ID":        room.ID,
+			"Name":      room.Name,
+			"Color":     room.Color,
+			"TextColor": room.TextColor,
+			"Message":   ban.Describe(),
+		})
+		return
+	}
+
 	failure := validateAndJoin(c, session, room, UrlJoin(room.ID))
 
 	if failure == nil {
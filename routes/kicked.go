@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/chat"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// GetKicked tells web users why they were taken out of the room.
+func GetKicked(c *gin.Context, session sessions.Session) {
+	room, found := chat.GetSingleRoom(c.Param("id"))
+	userId := session.Get("userId")
+
+	if !found || userId == nil {
+		c.Redirect(http.StatusFound, BustCache("/"))
+		return
+	}
+
+	notice, kicked := chat.GetKickNotice(chat.GetCombinedId(room.ID, userId.(string)))
+
+	if !kicked {
+		c.Redirect(http.StatusFound, BustCache("/"))
+		return
+	}
+
+	c.HTML(http.StatusOK, "kicked.html", gin.H{
+		"ID":        room.ID,
+		"Name":      room.Name,
+		"Color":     room.Color,
+		"TextColor": room.TextColor,
+		"Message":   notice.Message,
+	})
+}
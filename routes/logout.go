@@ -11,6 +11,9 @@ import (
 func PostLogout(c *gin.Context, session sessions.Session) {
 	roomId := c.PostForm("id")
 
+	endStaffSession(session)
+	session.Save()
+
 	userId := session.Get("userId")
 	combinedId := chat.GetCombinedId(roomId, userId.(string))
 	user, found := chat.GetUser(combinedId)
@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/chat"
+	"sort"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+func getPrivateTalkData(user chat.ChatUser, toUserId string, errors []string) gin.H {
+	others := lo.Filter(chat.GetAllUsers(), func(u chat.ChatUser, _ int) bool {
+		return u.ID != user.ID
+	})
+
+	sort.Slice(others, func(i, j int) bool {
+		return strings.ToLower(others[i].Nickname) < strings.ToLower(others[j].Nickname)
+	})
+
+	rooms := lo.SliceToMap(chat.GetAllRooms(), func(r chat.ChatRoom) (string, string) {
+		return r.ID, r.Name
+	})
+
+	return gin.H{
+		"ID":        user.RoomId,
+		"UserID":    user.ID,
+		"Nickname":  user.Nickname,
+		"Users":     others,
+		"RoomNames": rooms,
+		"To":        toUserId,
+		"Errors":    errors,
+	}
+}
+
+func GetPrivate(c *gin.Context, session sessions.Session) {
+	roomId := c.Param("id")
+
+	user, found := getSessionChatUser(session, roomId)
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.HTML(http.StatusOK, "private.html", gin.H{
+		"ID":       roomId,
+		"Nickname": user.Nickname,
+		"To":       c.Query("to"),
+	})
+}
+
+func GetPrivateThread(c *gin.Context, session sessions.Session) {
+	roomId := c.Param("id")
+
+	user, found := getSessionChatUser(session, roomId)
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	chat.MarkDirectMessagesRead(user.ID)
+
+	c.HTML(http.StatusOK, "private-thread.html", gin.H{
+		"ID":       roomId,
+		"UserID":   user.ID,
+		"Messages": chat.GetDirectMessages(user.ID),
+	})
+}
+
+func GetPrivateTalk(c *gin.Context, session sessions.Session) {
+	roomId := c.Param("id")
+
+	user, found := getSessionChatUser(session, roomId)
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.HTML(http.StatusOK, "private-talk.html", getPrivateTalkData(user, c.Query("to"), make([]string, 0)))
+}
+
+func PostPrivateTalk(c *gin.Context, session sessions.Session) {
+	roomId := c.Param("id")
+	toUserId := c.PostForm("to")
+	message := c.PostForm("message")
+
+	user, found := getSessionChatUser(session, roomId)
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	toUser, foundToUser := chat.GetUser(toUserId)
+
+	if !foundToUser {
+		c.HTML(http.StatusOK, "private-talk.html", getPrivateTalkData(user, "", []string{"This person is not online anymore."}))
+		return
+	}
+
+	sessionUserState := NewSessionUserState(c, session)
+
+	finalMessage, err := chat.ValidateDirectMessage(&sessionUserState, user, toUser, message)
+
+	if err != nil {
+		c.HTML(http.StatusOK, "private-talk.html", getPrivateTalkData(user, toUserId, []string{err.Error()}))
+		return
+	}
+
+	chat.SendDirectMessage(user, toUser, finalMessage)
+
+	data := getPrivateTalkData(user, toUserId, make([]string, 0))
+	data["UpdateThread"] = true
+
+	c.HTML(http.StatusOK, "private-talk.html", data)
+}
@@ -1,11 +1,14 @@
 package routes
 
 import (
+	"crypto/subtle"
 	"net"
+	"retro-chat-rooms/chat"
 	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type SessionUserState struct {
@@ -13,6 +16,17 @@ type SessionUserState struct {
 	ctx     *gin.Context
 }
 
+// getSessionChatUser finds the chat user the session has in the given room.
+func getSessionChatUser(session sessions.Session, roomId string) (chat.ChatUser, bool) {
+	userId := session.Get("userId")
+
+	if userId == nil {
+		return chat.ChatUser{}, false
+	}
+
+	return chat.GetUser(chat.GetCombinedId(roomId, userId.(string)))
+}
+
 func NewSessionUserState(ctx *gin.Context, session sessions.Session) SessionUserState {
 	return SessionUserState{session, ctx}
 }
@@ -58,6 +72,23 @@ func (sus *SessionUserState) SetLastScream(t time.Time) {
 	sus.session.Save()
 }
 
+// GetFormToken returns what the chat forms of the session have to send
+// back so other sites can't post them, it's made on first use.
+func (sus *SessionUserState) GetFormToken() string {
+	token, _ := sus.session.Get("formToken").(string)
+	if token == "" {
+		token = uuid.NewString()
+		sus.session.Set("formToken", token)
+		sus.session.Save()
+	}
+	return token
+}
+
+func (sus *SessionUserState) HasFormToken() bool {
+	token, _ := sus.session.Get("formToken").(string)
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(sus.ctx.PostForm("csrf"))) == 1
+}
+
 func (sus *SessionUserState) GetUserIP() string {
 	headers := [4]string{
 		"HTTP_CF_CONNECTING_IP", "HTTP_X_REAL_IP", "HTTP_X_FORWARDED_FOR", "REMOTE_ADDR",
@@ -36,8 +36,8 @@ func UrlLogout() string {
 	return BustCache("/logout")
 }
 
-func UrlCaptcha() string {
-	return BustCache("/chaptcha")
+func UrlCaptcha(id string) string {
+	return BustCache("/captcha/" + id)
 }
 
 func UrlChatHeader(id string) string {
@@ -53,7 +53,26 @@ func UrlChatUpdater(id string) string {
 }
 
 func UrlChatTalk(id string, to string) string {
-	urlA, err := url.Parse("/chat-talk/" + id)
+	return urlWithTo("/chat-talk/"+id, to)
+}
+
+func UrlChatReply(id string, to string, messageId string) string {
+	urlA, err := url.Parse(UrlChatTalk(id, to))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	values := urlA.Query()
+
+	values.Set("reply", messageId)
+
+	urlA.RawQuery = values.Encode()
+
+	return urlA.String()
+}
+
+func urlWithTo(path string, to string) string {
+	urlA, err := url.Parse(path)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -73,3 +92,31 @@ func UrlChatTalk(id string, to string) string {
 func UrlChatUsers(id string) string {
 	return BustCache("/chat-users/" + id)
 }
+
+func UrlVote(id string) string {
+	return BustCache("/vote/" + id)
+}
+
+func UrlPrivate(id string, to string) string {
+	return urlWithTo("/private/"+id, to)
+}
+
+func UrlPrivateThread(id string) string {
+	return BustCache("/private-thread/" + id)
+}
+
+func UrlPrivateTalk(id string, to string) string {
+	return urlWithTo("/private-talk/"+id, to)
+}
+
+func UrlKicked(id string) string {
+	return BustCache("/kicked/" + id)
+}
+
+func UrlChatModerate(id string, to string) string {
+	return urlWithTo("/chat-moderate/"+id, to)
+}
+
+func UrlChatReport(id string, messageId string) string {
+	return BustCache("/chat-report/" + id + "?m=" + url.QueryEscape(messageId))
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"net/http"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/polls"
+	"strconv"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func PostVote(c *gin.Context, session sessions.Session) {
+	roomId := c.Param("id")
+
+	userId := session.Get("userId")
+	if userId == nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	combinedId := chat.GetCombinedId(roomId, userId.(string))
+	user, found := chat.GetUser(combinedId)
+
+	if !found {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	sessionUserState := NewSessionUserState(c, session)
+	if !sessionUserState.HasFormToken() {
+		c.String(http.StatusForbidden, "Session expired, please join again.")
+		return
+	}
+
+	option, _ := strconv.Atoi(c.PostForm("o"))
+
+	err := polls.Vote(roomId, user, sessionUserState.GetUserIP(), option)
+	if err != nil {
+		chat.SendSystemNotice(user, "Couldn't vote: "+err.Error()+".")
+	} else {
+		chat.SendSystemNotice(user, "Thanks for voting!")
+	}
+
+	c.Redirect(http.StatusFound, UrlChatThread(roomId))
+}
@@ -11,9 +11,12 @@ const (
 	SERVER_MESSAGE_SENT              = 7
 	SERVER_USER_KICKED               = 8
 	SERVER_TIME                      = 9
+	SERVER_DIRECT_MESSAGE            = 10
+	SERVER_MESSAGES_PURGED           = 11
 
 	CLIENT_REGISTER_USER      = 100
 	CLIENT_SEND_MESSAGE       = 101
+	CLIENT_SEND_DIRECT        = 102
 	CLIENT_COLOR_LIST_REQUEST = 105
 	CLIENT_ROOM_LIST_REQUEST  = 106
 	CLIENT_PING               = 110
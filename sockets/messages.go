@@ -2,10 +2,14 @@ package sockets
 
 import (
 	"fmt"
+	"log"
 	"reflect"
+	"retro-chat-rooms/audit"
 	"retro-chat-rooms/chat"
+	"retro-chat-rooms/commands"
 	"retro-chat-rooms/floodcontrol"
 	"retro-chat-rooms/helpers"
+	"retro-chat-rooms/roles"
 	"strconv"
 	"strings"
 	"time"
@@ -19,6 +23,18 @@ func PushUserJoined(conn ISocket, user chat.ChatUser) {
 		Nickname: user.Nickname,
 		Color:    user.Color,
 		RoomID:   user.RoomId,
+		Role:     user.Role,
+	})
+	conn.Write(response)
+}
+
+func PushUserUpdated(conn ISocket, user chat.ChatUser) {
+	response := SerializeMessage(SERVER_USER_LIST_UPDATE, &ServerUserListAdd{
+		UserID:   user.ID,
+		Nickname: user.Nickname,
+		Color:    user.Color,
+		RoomID:   user.RoomId,
+		Role:     user.Role,
 	})
 	conn.Write(response)
 }
@@ -65,12 +81,18 @@ func PushMessage(conn ISocket, msg chat.ChatMessage, isHistory bool) {
 		return
 	}
 
+	if msg.Shadowed && msg.From != connUser.ID && !chat.CanSeeShadowed(connUser.ID) {
+		return
+	}
+
 	from := msg.GetFrom()
 	to := msg.GetTo()
 
 	var fromUser *ServerUserListAdd = nil
 	var toUser *ServerUserListAdd = nil
 	var systemMessageSubjectUser *ServerUserListAdd = nil
+	var replyTo *ServerQuotedMessage = nil
+	source := ""
 
 	if from != nil {
 		fromUser = &ServerUserListAdd{
@@ -78,7 +100,9 @@ func PushMessage(conn ISocket, msg chat.ChatMessage, isHistory bool) {
 			Nickname: from.Nickname,
 			Color:    from.Color,
 			RoomID:   from.RoomId,
+			Role:     from.Role,
 		}
+		source = chat.ClientInfoToMsgSource(from.Client)
 	}
 
 	if to != nil {
@@ -87,6 +111,7 @@ func PushMessage(conn ISocket, msg chat.ChatMessage, isHistory bool) {
 			Nickname: to.Nickname,
 			Color:    to.Color,
 			RoomID:   to.RoomId,
+			Role:     to.Role,
 		}
 	}
 
@@ -96,6 +121,15 @@ func PushMessage(conn ISocket, msg chat.ChatMessage, isHistory bool) {
 			Nickname: msg.SystemMessageSubject.Nickname,
 			Color:    msg.SystemMessageSubject.Color,
 			RoomID:   msg.SystemMessageSubject.RoomId,
+			Role:     msg.SystemMessageSubject.Role,
+		}
+	}
+
+	if msg.ReplyTo != nil {
+		replyTo = &ServerQuotedMessage{
+			MessageID: msg.ReplyTo.ID,
+			Nickname:  msg.ReplyTo.Nickname,
+			Excerpt:   msg.ReplyTo.Excerpt,
 		}
 	}
 
@@ -110,8 +144,11 @@ func PushMessage(conn ISocket, msg chat.ChatMessage, isHistory bool) {
 		SystemMessageSubject: SerializeSubObject(systemMessageSubjectUser),
 		Message:              msg.Message,
 		IsHistory:            strconv.FormatBool(isHistory),
-		Source:               chat.ClientInfoToMsgSource(from.Client),
+		Source:               source,
 		ShowClientIcon:       strconv.FormatBool(msg.ShowClientIcon),
+		MessageID:            msg.ID,
+		ReplyTo:              SerializeSubObject(replyTo),
+		Shadowed:             strconv.FormatBool(msg.Shadowed && msg.From != connUser.ID),
 	}
 
 	response := SerializeMessage(SERVER_MESSAGE_SENT, &message)
@@ -119,6 +156,50 @@ func PushMessage(conn ISocket, msg chat.ChatMessage, isHistory bool) {
 	conn.Write(response)
 }
 
+func PushMessagesPurged(conn ISocket, evt chat.ChatMessagesPurgedEvent) {
+	response := SerializeMessage(SERVER_MESSAGES_PURGED, &ServerMessagesPurged{
+		RoomID:     evt.RoomID,
+		UserID:     evt.UserID,
+		MessageIDs: evt.MessageIDs,
+	})
+
+	conn.Write(response)
+}
+
+func PushDirectMessage(conn ISocket, dm *chat.DirectMessage) {
+	connUser := conn.GetUser()
+
+	if dm.To.ID != connUser.ID && dm.From.ID != connUser.ID {
+		return
+	}
+
+	if dm.Shadowed && dm.From.ID != connUser.ID {
+		return
+	}
+
+	response := SerializeMessage(SERVER_DIRECT_MESSAGE, &ServerDirectMessage{
+		MessageID: dm.ID,
+		From: SerializeSubObject(&ServerUserListAdd{
+			UserID:   dm.From.ID,
+			Nickname: dm.From.Nickname,
+			Color:    dm.From.Color,
+			RoomID:   dm.From.RoomId,
+			Role:     dm.From.Role,
+		}),
+		To: SerializeSubObject(&ServerUserListAdd{
+			UserID:   dm.To.ID,
+			Nickname: dm.To.Nickname,
+			Color:    dm.To.Color,
+			RoomID:   dm.To.RoomId,
+			Role:     dm.To.Role,
+		}),
+		Time:    helpers.FormatTimestamp24H(dm.Time),
+		Message: dm.Message,
+	})
+
+	conn.Write(response)
+}
+
 func PushServerTime(conn ISocket) {
 	now := time.Now()
 
@@ -131,6 +212,44 @@ func PushServerTime(conn ISocket) {
 	conn.Write(response)
 }
 
+// registerStaff signs in a moderator from the config, returns
+// their combined ID.
+func registerStaff(conn ISocket, content RegisterUser, errors *[]string) string {
+	if _, found := chat.GetSingleRoom(content.RoomID); !found {
+		*errors = append(*errors, "Room not found.")
+		return ""
+	}
+
+	socketUserState := NewSocketsUserState(conn)
+	ip := socketUserState.GetUserIP()
+
+	staff, err := roles.Login(content.Nickname, content.Password, ip)
+
+	if err != nil {
+		*errors = append(*errors, roles.DescribeLoginError(content.Nickname, ip, err))
+
+		log.Printf("Failed staff login: %q from %s: %v", content.Nickname, ip, err)
+		audit.Record(audit.Entry{
+			Action:   audit.ACTION_LOGIN_FAILED,
+			RoomID:   content.RoomID,
+			Actor:    content.Nickname,
+			TargetIP: ip,
+			Reason:   err.Error(),
+		})
+		return ""
+	}
+
+	log.Printf("Staff login: %s from %s", staff.Nickname, ip)
+	audit.Record(audit.Entry{
+		Action:   audit.ACTION_LOGIN,
+		RoomID:   content.RoomID,
+		Actor:    staff.Nickname,
+		TargetIP: socketUserState.GetUserIP(),
+	})
+
+	return roles.SignIn(staff, content.RoomID, chat.ExtractClientInfo(content.Client), socketUserState.GetUserIP())
+}
+
 func registerUser(conn ISocket, msg string) {
 	content := DeserializeMessage(RegisterUser{}, msg)
 
@@ -141,25 +260,32 @@ func registerUser(conn ISocket, msg string) {
 
 	errors := make([]string, 0)
 
-	newUser := chat.ChatUser{
-		ID:        userId,
-		Nickname:  content.Nickname,
-		Color:     color,
-		RoomId:    content.RoomID,
-		DiscordId: "",
-		IsAdmin:   false,
-		Client:    chat.ExtractClientInfo(content.Client),
-	}
-
-	chat.ValidateUser(&socketUserState, newUser, &errors)
-
-	// YUCK THESE IFS
-	if len(errors) == 0 {
-		_, err := chat.RegisterUser(newUser)
-		if err != nil && err.Error() == "user exists" {
-			errors = append(errors, "Someone is already using this Nickname, try a different one.")
-		} else if err != nil {
-			errors = append(errors, "Couldn't register user, try again.")
+	if content.Password != "" {
+		userId = registerStaff(conn, content, &errors)
+	} else {
+		// No Fingerprint, native clients only send their name and version,
+		// shared by everyone using them, so the IP ban is all there is
+		newUser := chat.ChatUser{
+			ID:        userId,
+			Nickname:  content.Nickname,
+			Color:     color,
+			RoomId:    content.RoomID,
+			DiscordId: "",
+			IsAdmin:   false,
+			Client:    chat.ExtractClientInfo(content.Client),
+			IP:        socketUserState.GetUserIP(),
+		}
+
+		chat.ValidateUser(&socketUserState, newUser, &errors)
+
+		// YUCK THESE IFS
+		if len(errors) == 0 {
+			_, err := chat.RegisterUser(newUser)
+			if err != nil && err.Error() == "user exists" {
+				errors = append(errors, "Someone is already using this Nickname, try a different one.")
+			} else if err != nil {
+				errors = append(errors, "Couldn't register user, try again.")
+			}
 		}
 	}
 
@@ -177,6 +303,7 @@ func registerUser(conn ISocket, msg string) {
 			Nickname: user.Nickname,
 			Color:    user.Color,
 			RoomID:   user.RoomId,
+			Role:     user.Role,
 		})
 
 		conn.Write(userMsg)
@@ -195,6 +322,7 @@ func registerUser(conn ISocket, msg string) {
 		Nickname: user.Nickname,
 		Color:    user.Color,
 		RoomID:   user.RoomId,
+		Role:     user.Role,
 	})
 
 	InternalEvents.Publish(InternalUserRegisteredEvent{ConnectionID: conn.ID(), User: user})
@@ -217,7 +345,17 @@ func sendMessage(conn ISocket, msg string) {
 
 	user, foundUser := chat.GetUser(content.UserID)
 
-	if !foundUser {
+	// Only the user this connection registered can talk through it
+	if !foundUser || user.ID != conn.GetUser().ID {
+		return
+	}
+
+	socketUserState := NewSocketsUserState(conn)
+
+	if commands.IsCommand(content.Message) {
+		ctx := commands.NewContext(user, socketUserState.GetUserIP())
+		ctx.ReplyTo = content.ReplyTo
+		commands.Execute(ctx, content.Message)
 		return
 	}
 
@@ -236,8 +374,6 @@ func sendMessage(conn ISocket, msg string) {
 		return
 	}
 
-	socketUserState := NewSocketsUserState(conn)
-
 	message, isValid := chat.ValidateMessage(&socketUserState, chat.ChatMessage{
 		RoomID:               content.RoomID,
 		Time:                 time.Now().UTC(),
@@ -250,6 +386,7 @@ func sendMessage(conn ISocket, msg string) {
 		SystemMessageSubject: &user,
 		Source:               chat.ClientInfoToMsgSource(user.Client),
 		InvolvedUsers:        involvedUsers,
+		ReplyTo:              &chat.QuotedMessage{ID: content.ReplyTo},
 	})
 
 	if !isValid {
@@ -261,11 +398,49 @@ func sendMessage(conn ISocket, msg string) {
 			UserID:  user.ID,
 			Message: "You have been temporarily kicked out for flooding.",
 		})
+
+		chat.PublishModerationEvent(chat.ChatModerationEvent{
+			Action: chat.MODERATION_FLOOD_KICK,
+			RoomID: content.RoomID,
+			Target: user,
+			Reason: "Flooding the channel",
+		})
 	}
 
 	chat.SendMessage(&message)
 }
 
+func sendDirectMessage(conn ISocket, msg string) {
+	content := DeserializeMessage(SendDirectMessage{}, msg)
+
+	user, foundUser := chat.GetUser(content.UserID)
+
+	// Only the user this connection registered can talk through it
+	if !foundUser || user.ID != conn.GetUser().ID {
+		return
+	}
+
+	toUser, foundToUser := chat.GetUserByNickname(content.To)
+
+	if !foundToUser {
+		response := SerializeMessage(SERVER_ERROR, &ServerError{Message: "This person is not online."})
+		conn.Write(response)
+		return
+	}
+
+	socketUserState := NewSocketsUserState(conn)
+
+	message, err := chat.ValidateDirectMessage(&socketUserState, user, toUser, content.Message)
+
+	if err != nil {
+		response := SerializeMessage(SERVER_ERROR, &ServerError{Message: err.Error()})
+		conn.Write(response)
+		return
+	}
+
+	chat.SendDirectMessage(user, toUser, message)
+}
+
 func ping(conn ISocket, msg string) {
 	content := DeserializeMessage(Ping{}, msg)
 	fmt.Println("Acknowledged", content.UserId)
@@ -328,5 +503,7 @@ func ProcessMessage(conn ISocket, message []byte) {
 		ping(conn, msgContent)
 	case CLIENT_SEND_MESSAGE:
 		sendMessage(conn, msgContent)
+	case CLIENT_SEND_DIRECT:
+		sendDirectMessage(conn, msgContent)
 	}
 }
@@ -117,9 +117,18 @@ func observeRoomEvents(connection ISocket, events pubsub.Pubsub, ctx context.Con
 			case chat.ChatUserLeftEvent:
 				PushUserLeft(connection, evt.User)
 
+			case chat.ChatUserUpdatedEvent:
+				PushUserUpdated(connection, evt.User)
+
 			case chat.ChatUserKickedEvent:
 				PushUserKickedMessage(connection, evt)
 
+			case chat.DirectMessageEvent:
+				PushDirectMessage(connection, evt.Message)
+
+			case chat.ChatMessagesPurgedEvent:
+				PushMessagesPurged(connection, evt)
+
 			}
 		}
 	}
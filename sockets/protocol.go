@@ -128,6 +128,12 @@ func injectIntoInterface(val interface{}, msgContent string) {
 			metadataOrder := fieldType.Tag.Get("fieldOrder")
 
 			order, _ := strconv.Atoi(metadataOrder)
+
+			// Older clients don't send the newer fields
+			if order >= len(orderedValues) {
+				continue
+			}
+
 			value := orderedValues[order]
 
 			parseAndSet(fieldValue, value)
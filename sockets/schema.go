@@ -15,6 +15,15 @@ type SendMessage struct {
 	Message    string `fieldOrder:"3"`
 	Privately  string `fieldOrder:"4"`
 	RoomID     string `fieldOrder:"5"`
+	// ID of the message this one replies to, optional
+	ReplyTo string `fieldOrder:"6"`
+}
+
+type SendDirectMessage struct {
+	UserID string `fieldOrder:"0"`
+	// Nickname of who it goes to, they can be in any room
+	To      string `fieldOrder:"1"`
+	Message string `fieldOrder:"2"`
 }
 
 type ColorListRequest struct {
@@ -38,6 +47,8 @@ type RegisterUser struct {
 	Color    string `fieldOrder:"1"`
 	RoomID   string `fieldOrder:"2"`
 	Client   string `fieldOrder:"3"`
+	// Only for moderators, optional
+	Password string `fieldOrder:"4"`
 }
 
 type RegisterUserResponse struct {
@@ -45,6 +56,7 @@ type RegisterUserResponse struct {
 	Nickname string `fieldOrder:"1"`
 	Color    string `fieldOrder:"2"`
 	RoomID   string `fieldOrder:"3"`
+	Role     string `fieldOrder:"4"`
 }
 
 type ServerUserListAdd struct {
@@ -52,6 +64,7 @@ type ServerUserListAdd struct {
 	Nickname string `fieldOrder:"1"`
 	Color    string `fieldOrder:"2"`
 	RoomID   string `fieldOrder:"3"`
+	Role     string `fieldOrder:"4"`
 }
 
 type ServerUserListRemove struct {
@@ -81,6 +94,30 @@ type ServerMessageSent struct {
 	Message              string `fieldOrder:"9"`
 	Source               string `fieldOrder:"10"`
 	ShowClientIcon       string `fieldOrder:"11"`
+	MessageID            string `fieldOrder:"12"`
+	ReplyTo              string `fieldOrder:"13"`
+	// Only moderators get shadowed messages from others
+	Shadowed string `fieldOrder:"14"`
+}
+
+type ServerQuotedMessage struct {
+	MessageID string `fieldOrder:"0"`
+	Nickname  string `fieldOrder:"1"`
+	Excerpt   string `fieldOrder:"2"`
+}
+
+type ServerDirectMessage struct {
+	MessageID string `fieldOrder:"0"`
+	From      string `fieldOrder:"1"`
+	To        string `fieldOrder:"2"`
+	Time      string `fieldOrder:"3"`
+	Message   string `fieldOrder:"4"`
+}
+
+type ServerMessagesPurged struct {
+	RoomID     string   `fieldOrder:"0"`
+	UserID     string   `fieldOrder:"1"`
+	MessageIDs []string `fieldOrder:"2"`
 }
 
 type ServerTimeMessage struct {
@@ -0,0 +1,60 @@
+package spam
+
+// Defaults for anything left out of the spam config
+const (
+	// Messages and strikes older than this are forgotten
+	DEFAULT_WINDOW_SEC = 60
+	// Sending the same thing this many times within the window is spam
+	DEFAULT_MAX_REPEATS = 3
+	// Upper case letters, only checked on messages with at least
+	// MIN_CAPS_LETTERS letters
+	DEFAULT_MAX_CAPS_PERCENT = 70
+	// Same character over and over, like "hiiiiiiiiiiiiii"
+	DEFAULT_MAX_CHARACTER_RUN = 12
+	DEFAULT_MAX_MENTIONS      = 4
+	DEFAULT_MAX_LINKS         = 2
+	// Strike totals within the window that trigger each action
+	DEFAULT_WARN_SCORE = 1
+	DEFAULT_DROP_SCORE = 3
+	DEFAULT_MUTE_SCORE = 6
+	DEFAULT_MUTE_MIN   = 5
+)
+
+const MIN_CAPS_LETTERS = 8
+
+// Messages this much alike count as repeats, 1 is identical
+const SIMILARITY_THRESHOLD = 0.85
+
+// How many strikes each kind of spam is worth
+const (
+	SCORE_REPEAT          = 2
+	SCORE_CAPS            = 1
+	SCORE_CHARACTER_FLOOD = 1
+	SCORE_MENTIONS        = 2
+	SCORE_LINKS           = 2
+)
+
+const (
+	REASON_REPEAT          = "repeated message"
+	REASON_CAPS            = "excessive caps"
+	REASON_CHARACTER_FLOOD = "character flooding"
+	REASON_MENTIONS        = "mass mentions"
+	REASON_LINKS           = "link spam"
+)
+
+var REASONS = []string{
+	REASON_REPEAT,
+	REASON_CAPS,
+	REASON_CHARACTER_FLOOD,
+	REASON_MENTIONS,
+	REASON_LINKS,
+}
+
+const (
+	ACTION_NONE = ""
+	ACTION_WARN = "warn"
+	ACTION_DROP = "drop"
+	ACTION_MUTE = "mute"
+)
+
+var ACTIONS = []string{ACTION_WARN, ACTION_DROP, ACTION_MUTE}
@@ -0,0 +1,32 @@
+package spam
+
+import "time"
+
+// Verdict is what the spam filter thinks of a message.
+type Verdict struct {
+	Reasons []string
+	// Strikes the user collected within the window, this message included
+	Score  int
+	Action string
+}
+
+// Counter is how often something happened since the server started.
+type Counter struct {
+	Name  string
+	Count int
+}
+
+type sentMessage struct {
+	Time time.Time
+	Text string
+}
+
+type strike struct {
+	Time  time.Time
+	Score int
+}
+
+type history struct {
+	Messages []sentMessage
+	Strikes  []strike
+}
@@ -0,0 +1,254 @@
+package spam
+
+import (
+	"regexp"
+	"retro-chat-rooms/config"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/samber/lo"
+)
+
+var (
+	mutex     sync.Mutex
+	histories = map[string]*history{}
+
+	reasonCounts = map[string]int{}
+	actionCounts = map[string]int{}
+
+	linkExpr    = regexp.MustCompile(`(?i)\b(https?://|www\.)\S+`)
+	mentionExpr = regexp.MustCompile(`(^|\s)@\S+`)
+)
+
+func orDefault(value int, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func window() time.Duration {
+	return time.Duration(orDefault(config.Current.Spam.WindowSec, DEFAULT_WINDOW_SEC)) * time.Second
+}
+
+func MuteMinutes() int {
+	return orDefault(config.Current.Spam.MuteMin, DEFAULT_MUTE_MIN)
+}
+
+func IsEnabled() bool {
+	return !config.Current.Spam.Disabled
+}
+
+// normalize makes near identical messages look the same, ignoring case,
+// punctuation and spacing.
+func normalize(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// similarity is 1 for identical strings down to 0 for nothing in common.
+func similarity(a string, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	// Levenshtein distance keeping only two rows around
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
+
+func capsPercent(text string) (int, int) {
+	letters, upper := 0, 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	if letters == 0 {
+		return 0, 0
+	}
+	return upper * 100 / letters, letters
+}
+
+func longestRun(text string) int {
+	longest, run := 0, 0
+	var last rune
+	for i, r := range text {
+		if i > 0 && r == last && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		last = r
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+func countRepeats(h *history, text string) int {
+	normalized := normalize(text)
+	if normalized == "" {
+		return 0
+	}
+
+	return lo.CountBy(h.Messages, func(m sentMessage) bool {
+		return similarity(m.Text, normalized) >= SIMILARITY_THRESHOLD
+	})
+}
+
+// Check scores the message and records it, key identifies the sender.
+// mentions is how many people the message names, on top of @mentions.
+func Check(key string, text string, mentions int) Verdict {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	settings := config.Current.Spam
+	now := time.Now().UTC()
+	since := now.Add(-window())
+
+	h, found := histories[key]
+	if !found {
+		h = &history{}
+		histories[key] = h
+	}
+
+	h.Messages = lo.Filter(h.Messages, func(m sentMessage, _ int) bool { return m.Time.After(since) })
+	h.Strikes = lo.Filter(h.Strikes, func(s strike, _ int) bool { return s.Time.After(since) })
+
+	verdict := Verdict{Reasons: []string{}}
+	score := 0
+
+	// This message is the last of the repeats
+	if countRepeats(h, text)+1 >= orDefault(settings.MaxRepeats, DEFAULT_MAX_REPEATS) {
+		verdict.Reasons = append(verdict.Reasons, REASON_REPEAT)
+		score += SCORE_REPEAT
+	}
+
+	if percent, letters := capsPercent(text); letters >= MIN_CAPS_LETTERS && percent > orDefault(settings.MaxCapsPercent, DEFAULT_MAX_CAPS_PERCENT) {
+		verdict.Reasons = append(verdict.Reasons, REASON_CAPS)
+		score += SCORE_CAPS
+	}
+
+	if longestRun(text) > orDefault(settings.MaxCharacterRun, DEFAULT_MAX_CHARACTER_RUN) {
+		verdict.Reasons = append(verdict.Reasons, REASON_CHARACTER_FLOOD)
+		score += SCORE_CHARACTER_FLOOD
+	}
+
+	if mentions+len(mentionExpr.FindAllString(text, -1)) > orDefault(settings.MaxMentions, DEFAULT_MAX_MENTIONS) {
+		verdict.Reasons = append(verdict.Reasons, REASON_MENTIONS)
+		score += SCORE_MENTIONS
+	}
+
+	if len(linkExpr.FindAllString(text, -1)) > orDefault(settings.MaxLinks, DEFAULT_MAX_LINKS) {
+		verdict.Reasons = append(verdict.Reasons, REASON_LINKS)
+		score += SCORE_LINKS
+	}
+
+	h.Messages = append(h.Messages, sentMessage{Time: now, Text: normalize(text)})
+
+	if score == 0 {
+		return verdict
+	}
+
+	h.Strikes = append(h.Strikes, strike{Time: now, Score: score})
+	verdict.Score = lo.SumBy(h.Strikes, func(s strike) int { return s.Score })
+
+	switch {
+	case verdict.Score >= orDefault(settings.MuteScore, DEFAULT_MUTE_SCORE):
+		verdict.Action = ACTION_MUTE
+		// Start over once they can talk again
+		h.Strikes = nil
+	case verdict.Score >= orDefault(settings.DropScore, DEFAULT_DROP_SCORE):
+		verdict.Action = ACTION_DROP
+	case verdict.Score >= orDefault(settings.WarnScore, DEFAULT_WARN_SCORE):
+		verdict.Action = ACTION_WARN
+	}
+
+	for _, reason := range verdict.Reasons {
+		reasonCounts[reason]++
+	}
+	if verdict.Action != ACTION_NONE {
+		actionCounts[verdict.Action]++
+	}
+
+	return verdict
+}
+
+// Score returns the strikes the sender has right now.
+func Score(key string) int {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	h, found := histories[key]
+	if !found {
+		return 0
+	}
+
+	since := time.Now().UTC().Add(-window())
+	return lo.SumBy(h.Strikes, func(s strike) int {
+		if s.Time.After(since) {
+			return s.Score
+		}
+		return 0
+	})
+}
+
+// EvictIdle forgets senders with nothing left in the window, returns
+// how many were dropped.
+func EvictIdle() int {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	since := time.Now().UTC().Add(-window())
+	evicted := 0
+
+	for key, h := range histories {
+		recent := lo.ContainsBy(h.Messages, func(m sentMessage) bool { return m.Time.After(since) }) ||
+			lo.ContainsBy(h.Strikes, func(s strike) bool { return s.Time.After(since) })
+
+		if !recent {
+			delete(histories, key)
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+// Counters returns how many times each kind of spam was caught and each
+// action taken since the server started.
+func Counters() ([]Counter, []Counter) {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	reasons := lo.Map(REASONS, func(name string, _ int) Counter { return Counter{name, reasonCounts[name]} })
+	actions := lo.Map(ACTIONS, func(name string, _ int) Counter { return Counter{name, actionCounts[name]} })
+
+	return reasons, actions
+}
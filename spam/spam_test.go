@@ -0,0 +1,130 @@
+package spam
+
+import (
+	"retro-chat-rooms/config"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func reset() {
+	defer mutex.Unlock()
+	mutex.Lock()
+
+	histories = map[string]*history{}
+	config.Current.Spam = config.SpamConfig{}
+}
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"hello", "hello", 1},
+		{"hello", "", 0},
+		{"abc", "xyz", 0},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"héllo", "hello", 0.8},
+	}
+
+	for _, c := range cases {
+		if got := similarity(c.a, c.b); got < c.want-0.001 || got > c.want+0.001 {
+			t.Errorf("similarity(%q, %q) = %.3f, want %.3f", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestCheckReasons(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		mentions int
+		want     []string
+	}{
+		{"normal", "hey everyone, how's it going?", 0, []string{}},
+		{"caps", "WHY IS NOBODY ANSWERING ME", 0, []string{REASON_CAPS}},
+		{"short caps", "OK LOL", 0, []string{}},
+		{"character flood", "hiiiiiiiiiiiiiiii", 0, []string{REASON_CHARACTER_FLOOD}},
+		{"spaces don't flood", "hi" + strings.Repeat(" ", 20) + "there", 0, []string{}},
+		{"mentions", "@a @b @c @d @e", 0, []string{REASON_MENTIONS}},
+		{"named users count", "@a @b hello", 3, []string{REASON_MENTIONS}},
+		{"links", "http://a.example www.b.example https://c.example", 0, []string{REASON_LINKS}},
+		{"two links", "http://a.example and http://b.example", 0, []string{}},
+	}
+
+	for _, c := range cases {
+		reset()
+		if got := Check("someone", c.text, c.mentions).Reasons; !slices.Equal(got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRepeatsAndActions(t *testing.T) {
+	reset()
+
+	messages := []struct {
+		text   string
+		action string
+	}{
+		{"buy cheap stuff now", ACTION_NONE},
+		{"Buy cheap stuff now!", ACTION_NONE},
+		// Third repeat, 2 strikes
+		{"buy cheap stuff now!!", ACTION_WARN},
+		// 4 strikes
+		{"buy  cheap stuff now", ACTION_DROP},
+		// 6 strikes
+		{"BUY CHEAP STUFF NOW", ACTION_MUTE},
+		// Strikes start over after a mute, this is just the repeat
+		{"buy cheap stuff now", ACTION_WARN},
+	}
+
+	for i, m := range messages {
+		if got := Check("spammer", m.text, 0).Action; got != m.action {
+			t.Errorf("message %d %q: action %q, want %q", i+1, m.text, got, m.action)
+		}
+	}
+
+	if Check("someone else", "buy cheap stuff now", 0).Action != ACTION_NONE {
+		t.Error("another sender got the spammer's repeats")
+	}
+}
+
+func TestWindow(t *testing.T) {
+	reset()
+
+	Check("someone", "WHY IS NOBODY ANSWERING ME", 0)
+	if got := Score("someone"); got != SCORE_CAPS {
+		t.Fatalf("score %d, want %d", got, SCORE_CAPS)
+	}
+
+	mutex.Lock()
+	h := histories["someone"]
+	h.Strikes[0].Time = time.Now().Add(-2 * window())
+	h.Messages[0].Time = h.Strikes[0].Time
+	mutex.Unlock()
+
+	if got := Score("someone"); got != 0 {
+		t.Errorf("strikes outside the window still count, score %d", got)
+	}
+	if evicted := EvictIdle(); evicted != 1 {
+		t.Errorf("evicted %d, want the quiet sender", evicted)
+	}
+	if Score("nobody") != 0 {
+		t.Error("an unknown sender has strikes")
+	}
+}
+
+func TestSettings(t *testing.T) {
+	reset()
+	config.Current.Spam = config.SpamConfig{MaxCapsPercent: 90, WarnScore: 5}
+
+	if got := Check("someone", "WHY IS NOBODY ANSWERING me", 0); len(got.Reasons) != 0 {
+		t.Errorf("caps under the configured limit flagged: %v", got.Reasons)
+	}
+	if got := Check("someone", "WHY IS NOBODY ANSWERING ME", 0); got.Action != ACTION_NONE || got.Score != SCORE_CAPS {
+		t.Errorf("got %+v, want a strike without a warning", got)
+	}
+}
@@ -0,0 +1,8 @@
+package storage
+
+const (
+	BACKEND_FILE   = "file"
+	BACKEND_MEMORY = "memory"
+
+	DEFAULT_DIRECTORY = "data"
+)
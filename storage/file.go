@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FileBackend keeps documents as JSON files and logs as
+// JSON lines files inside a directory.
+type FileBackend struct {
+	directory string
+	mutex     sync.Mutex
+}
+
+// compile time proof of interface implementation
+var _ Backend = (*FileBackend)(nil)
+
+func NewFileBackend(directory string) *FileBackend {
+	return &FileBackend{directory: directory}
+}
+
+func (fb *FileBackend) path(name string, ext string) (string, error) {
+	path := filepath.Join(fb.directory, filepath.FromSlash(name)+ext)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (fb *FileBackend) Load(name string, v interface{}) (bool, error) {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	path, err := fb.path(name, ".json")
+	if err != nil {
+		return false, err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(b, v)
+}
+
+func (fb *FileBackend) Save(name string, v interface{}) error {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	path, err := fb.path(name, ".json")
+	if err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash
+	// doesn't leave half a document behind.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (fb *FileBackend) Append(name string, record interface{}) error {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	path, err := fb.path(name, ".jsonl")
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(b, '\n'))
+	return err
+}
+
+func (fb *FileBackend) Scan(name string, fn func(data []byte) error) error {
+	fb.mutex.Lock()
+	path, err := fb.path(name, ".jsonl")
+	fb.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (fb *FileBackend) Rewrite(name string, keep func(data []byte) bool) error {
+	fb.mutex.Lock()
+	defer fb.mutex.Unlock()
+
+	path, err := fb.path(name, ".jsonl")
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	kept := make([]byte, 0, len(b))
+	for _, line := range bytes.Split(b, []byte{'\n'}) {
+		if len(line) > 0 && keep(line) {
+			kept = append(append(kept, line...), '\n')
+		}
+	}
+
+	// Same as Save, never leave half a log behind
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, kept, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (fb *FileBackend) Logs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(fb.directory, filepath.FromSlash(dir)))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl") {
+			names = append(names, dir+"/"+strings.TrimSuffix(entry.Name(), ".jsonl"))
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+// MemoryBackend keeps everything in memory, nothing survives a restart.
+type MemoryBackend struct {
+	documents map[string][]byte
+	logs      map[string][][]byte
+	mutex     sync.Mutex
+}
+
+// compile time proof of interface implementation
+var _ Backend = (*MemoryBackend)(nil)
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		documents: make(map[string][]byte),
+		logs:      make(map[string][][]byte),
+	}
+}
+
+func (mb *MemoryBackend) Load(name string, v interface{}) (bool, error) {
+	mb.mutex.Lock()
+	b, found := mb.documents[name]
+	mb.mutex.Unlock()
+
+	if !found {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(b, v)
+}
+
+func (mb *MemoryBackend) Save(name string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	mb.mutex.Lock()
+	mb.documents[name] = b
+	mb.mutex.Unlock()
+
+	return nil
+}
+
+func (mb *MemoryBackend) Append(name string, record interface{}) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	mb.mutex.Lock()
+	mb.logs[name] = append(mb.logs[name], b)
+	mb.mutex.Unlock()
+
+	return nil
+}
+
+func (mb *MemoryBackend) Scan(name string, fn func(data []byte) error) error {
+	mb.mutex.Lock()
+	records := append([][]byte{}, mb.logs[name]...)
+	mb.mutex.Unlock()
+
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (mb *MemoryBackend) Rewrite(name string, keep func(data []byte) bool) error {
+	defer mb.mutex.Unlock()
+	mb.mutex.Lock()
+
+	mb.logs[name] = lo.Filter(mb.logs[name], func(record []byte, _ int) bool { return keep(record) })
+	return nil
+}
+
+func (mb *MemoryBackend) Logs(dir string) ([]string, error) {
+	defer mb.mutex.Unlock()
+	mb.mutex.Lock()
+
+	names := lo.Filter(lo.Keys(mb.logs), func(name string, _ int) bool {
+		rest, found := strings.CutPrefix(name, dir+"/")
+		return found && !strings.Contains(rest, "/")
+	})
+	sort.Strings(names)
+
+	return names, nil
+}
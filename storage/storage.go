@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"log"
+	"retro-chat-rooms/config"
+)
+
+// Backend persists whole documents (read and written at once)
+// and append-only logs (one record at a time).
+type Backend interface {
+	// Load reads the document into v, returns false if it was never saved.
+	Load(name string, v interface{}) (bool, error)
+	Save(name string, v interface{}) error
+	Append(name string, record interface{}) error
+	// Scan calls fn with every record of the log, oldest first.
+	Scan(name string, fn func(data []byte) error) error
+	// Rewrite keeps only the records of the log for which keep is true.
+	Rewrite(name string, keep func(data []byte) bool) error
+	// Logs lists the names of the logs directly inside dir, sorted.
+	Logs(dir string) ([]string, error)
+}
+
+func newBackend(cfg config.StorageConfig) Backend {
+	switch cfg.Backend {
+	case BACKEND_MEMORY:
+		return NewMemoryBackend()
+	case BACKEND_FILE, "":
+		directory := cfg.Directory
+		if directory == "" {
+			directory = DEFAULT_DIRECTORY
+		}
+		return NewFileBackend(directory)
+	}
+
+	log.Printf("Unknown storage backend %q, keeping data in memory", cfg.Backend)
+	return NewMemoryBackend()
+}
+
+var Current = newBackend(config.Current.Storage)
@@ -0,0 +1,21 @@
+package tasks
+
+import (
+	"retro-chat-rooms/audit"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/discord"
+)
+
+// MirrorAuditToDiscord posts every audit entry to the moderation channel.
+func MirrorAuditToDiscord() {
+	if config.Current.DiscordModerationChannel == "" {
+		return
+	}
+
+	c := audit.Events.Subscribe("discord-bot")
+	for message := range c {
+		if e, ok := message.(audit.Entry); ok {
+			discord.Instance.SendSystemMessage(config.Current.DiscordModerationChannel, "`"+audit.Format(e)+"`")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package tasks
+
+import (
+	"log"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/chatlog"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/pubsub"
+)
+
+func logRoomEvents(logger *chatlog.Logger, events pubsub.Pubsub) {
+	c := events.Subscribe("chat-logger")
+	defer logger.Close()
+
+	for message := range c {
+		var err error
+
+		switch evt := message.(type) {
+		case chat.ChatMessageEvent:
+			if evt.Message != nil {
+				err = logger.LogMessage(evt.Message)
+			}
+		case chat.ChatUserJoinedEvent:
+			err = logger.LogJoin(evt.User)
+		case chat.ChatUserLeftEvent:
+			err = logger.LogLeave(evt.User)
+		case chat.ChatModerationEvent:
+			err = logger.LogModeration(evt)
+		}
+
+		if err != nil {
+			log.Printf("Error writing chat log: %v", err)
+		}
+	}
+}
+
+func ObserveMessagesToLogs() {
+	if !config.Current.Logs.Enabled {
+		return
+	}
+
+	for roomId, events := range chat.RoomEvents {
+		go logRoomEvents(chatlog.NewLogger(roomId, config.Current.Logs), events)
+	}
+}
@@ -0,0 +1,14 @@
+package tasks
+
+import (
+	"retro-chat-rooms/polls"
+	"time"
+)
+
+func ClosePolls() {
+	for {
+		polls.CloseExpiredPolls()
+
+		time.Sleep(5000 * time.Millisecond)
+	}
+}
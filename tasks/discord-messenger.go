@@ -1,13 +1,20 @@
 package tasks
 
 import (
+	"html"
 	"regexp"
+	"retro-chat-rooms/bans"
 	"retro-chat-rooms/chat"
+	"retro-chat-rooms/commands"
 	"retro-chat-rooms/discord"
+	"retro-chat-rooms/polls"
 	"retro-chat-rooms/pubsub"
+	"retro-chat-rooms/roles"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
 )
 
 func observeRoomMessages(roomId string, events pubsub.Pubsub) {
@@ -16,13 +23,39 @@ func observeRoomMessages(roomId string, events pubsub.Pubsub) {
 		switch evt := message.(type) {
 		case chat.ChatMessageEvent:
 			msg := evt.Message
-			if msg != nil && msg.Source != chat.MSG_SOURCE_DISCORD && !msg.IsSystemMessage {
+			if msg != nil && msg.Source != chat.MSG_SOURCE_DISCORD && !msg.IsSystemMessage && !msg.Shadowed {
 				room, _ := chat.GetSingleRoom(roomId)
 				if room.DiscordChannel != "" {
 					discord.Instance.SendMessage(room.DiscordChannel, msg)
 				}
 			}
 
+		case chat.DirectMessageEvent:
+			dm := evt.Message
+			// It's published in both rooms, only send it once
+			if roomId == dm.To.RoomId && dm.To.IsDiscordUser() && dm.From.ID != dm.To.ID && !dm.Shadowed {
+				discord.Instance.SendDirectMessage(dm.To.DiscordId, formatDirectMessageForDiscord(dm))
+			}
+
+		case chat.ChatModerationEvent:
+			if message, tell := formatModerationForDiscord(evt); tell {
+				room, _ := chat.GetSingleRoom(roomId)
+				discord.Instance.SendSystemMessage(room.DiscordChannel, message)
+
+				if evt.Target.IsDiscordUser() {
+					discord.Instance.SendDirectMessage(evt.Target.DiscordId, "You were removed from "+room.Name+": "+message)
+				}
+			}
+
+		case chat.ChatMessagesPurgedEvent:
+			discord.Instance.DeleteMessages(evt.MessageIDs)
+
+		case polls.PollClosedEvent:
+			room, _ := chat.GetSingleRoom(roomId)
+			discord.Instance.SendSystemMessage(
+				room.DiscordChannel,
+				"Poll by "+evt.Poll.CreatedBy.Nickname+" is closed! "+polls.FormatPoll(evt.Poll, true),
+			)
 		}
 	}
 }
@@ -33,17 +66,113 @@ func ObserveMessagesToDiscord() {
 	}
 }
 
+// formatModerationForDiscord describes kicks and bans, other actions
+// aren't announced.
+func formatModerationForDiscord(evt chat.ChatModerationEvent) (string, bool) {
+	var action string
+
+	switch evt.Action {
+	case chat.MODERATION_KICK, chat.MODERATION_FLOOD_KICK:
+		action = "kicked out"
+	case chat.MODERATION_BAN:
+		action = "banned"
+	default:
+		return "", false
+	}
+
+	message := "**" + html.UnescapeString(evt.Target.Nickname) + "** was " + action
+	if evt.Actor != "" {
+		message += " by **" + html.UnescapeString(evt.Actor) + "**"
+	}
+	if evt.Reason != "" {
+		message += ": " + evt.Reason
+	}
+
+	return message, true
+}
+
+// deliverMemosOnDiscord sends a Discord user their memos privately,
+// they never see the notices web users get them in.
+func deliverMemosOnDiscord(user chat.ChatUser) {
+	for _, memo := range chat.TakeMemos(user.Nickname) {
+		discord.Instance.SendDirectMessage(user.DiscordId,
+			"**"+html.UnescapeString(memo.From)+"** left you a memo at "+memo.SentAt()+": "+memo.Message)
+	}
+}
+
+func formatDirectMessageForDiscord(dm *chat.DirectMessage) string {
+	room, _ := chat.GetSingleRoom(dm.From.RoomId)
+
+	return "**" + dm.From.Nickname + "** (" + room.Name + ") privately: " + html.UnescapeString(dm.Message) +
+		"\n-# Reply with `" + dm.From.Nickname + ": your message`"
+}
+
+// onReceiveDiscordDirectMessage handles DMs to the bot, they are
+// forwarded as direct messages in the format "nickname: message".
+func onReceiveDiscordDirectMessage(m *discordgo.MessageCreate) {
+	reply := func(message string) {
+		discord.Instance.SendSystemMessage(m.ChannelID, message)
+	}
+
+	user, found := chat.GetUserByDiscordId(m.Author.ID)
+	if !found {
+		reply("You need to say something in one of the chat channels before sending private messages.")
+		return
+	}
+
+	nickname, content, hasNickname := strings.Cut(m.Content, ":")
+	if !hasNickname || strings.TrimSpace(content) == "" {
+		reply("To send a private message, write `nickname: your message`.")
+		return
+	}
+
+	toUser, found := chat.GetUserByNickname(nickname)
+	if !found || toUser.ID == user.ID {
+		reply("**" + strings.TrimSpace(nickname) + "** is not online.")
+		return
+	}
+
+	userState := NewDiscordUserState(user)
+	message, err := chat.ValidateDirectMessage(&userState, user, toUser, strings.TrimSpace(content))
+	if err != nil {
+		reply(err.Error())
+		return
+	}
+
+	chat.SendDirectMessage(user, toUser, message)
+}
+
 func OnReceiveDiscordMessage(m *discordgo.MessageCreate) {
 	content := m.Content
 
+	if _, banned := bans.Check(bans.Candidate{DiscordId: m.Author.ID, Nickname: m.Author.Username}); banned {
+		return
+	}
+
+	if m.GuildID == "" {
+		onReceiveDiscordDirectMessage(m)
+		return
+	}
+
 	roomId, found := chat.FindRoomIdByDiscordChannel(m.ChannelID)
 
 	if !found {
 		return
 	}
 
+	var discordRoles []string
+	if m.Member != nil {
+		discordRoles = m.Member.Roles
+	}
+	role := roles.ForDiscordMember(m.Author.ID, discordRoles, roomId)
+
 	combinedId := chat.GetCombinedId(roomId, m.Author.ID)
 	user, found := chat.GetUserByDiscordId(m.Author.ID)
+	if found && user.RoomId == roomId && roles.Outranks(role, user.Role) {
+		// Discord roles can change at any time, pick up new ones
+		user, _ = chat.SetUserRole(user.ID, role)
+	}
+
 	if !found {
 		user = chat.ChatUser{
 			RoomId:    roomId,
@@ -52,12 +181,51 @@ func OnReceiveDiscordMessage(m *discordgo.MessageCreate) {
 			Color:     chat.USER_COLOR_BLACK,
 			DiscordId: m.Author.ID,
 			IsAdmin:   false,
+			Role:      role,
 			Client: chat.ClientInfo{
 				Plat: chat.CLIENT_PLATFORM_DISCORD,
 			},
 		}
 		chat.RegisterUser(user)
+		deliverMemosOnDiscord(user)
 	}
+
+	// Discord has its own slash commands, so ours work with ! as well.
+	// Anything else starting with / or ! is bridged like any message.
+	name, _, _ := strings.Cut(strings.TrimSpace(content), " ")
+	isCommand := len(name) > 1 && (name[0] == '/' || name[0] == '!') && commands.IsKnown(name[1:])
+
+	if isCommand {
+		// Act from the channel's room, with whatever role they have there
+		actor := user
+		actor.RoomId = roomId
+		if user.RoomId != roomId || roles.Outranks(role, user.Role) {
+			actor.Role = role
+		}
+
+		// Answer privately and keep moderation out of the channel
+		discord.Instance.DeleteChannelMessage(m.ChannelID, m.ID)
+
+		ctx := commands.Context{
+			User: actor,
+			Reply: func(message string) {
+				discord.Instance.SendDirectMessage(m.Author.ID, html.UnescapeString(message))
+			},
+		}
+
+		if m.MessageReference != nil {
+			ctx.ReplyTo, _ = discord.FindChatMessageId(m.MessageReference.MessageID)
+		}
+
+		commands.Execute(ctx, "/"+strings.TrimSpace(content)[1:])
+		return
+	}
+
+	if notice, muted := chat.MuteNotice(user); muted {
+		discord.Instance.SendSystemMessage(m.ChannelID, "<@"+m.Author.ID+"> Sorry, "+notice)
+		return
+	}
+
 	now := time.Now().UTC()
 
 	messageMentionExpr := regexp.MustCompile(`^\s*@([^:]+):\s*(.+)`)
@@ -77,7 +245,19 @@ func OnReceiveDiscordMessage(m *discordgo.MessageCreate) {
 		}
 	}
 
+	var replyTo *chat.QuotedMessage
+	if m.MessageReference != nil {
+		if chatId, found := discord.FindChatMessageId(m.MessageReference.MessageID); found {
+			quoted, _ := chat.FindRoomMessage(roomId, chatId)
+			replyTo = chat.QuoteMessage(quoted)
+		}
+	}
+
+	messageId := uuid.NewString()
+	discord.TrackMessage(messageId, m.ChannelID, m.ID)
+
 	chat.SendMessage(&chat.ChatMessage{
+		ID:              messageId,
 		RoomID:          roomId,
 		Time:            now,
 		Message:         content,
@@ -88,5 +268,6 @@ func OnReceiveDiscordMessage(m *discordgo.MessageCreate) {
 		Source:          chat.ClientInfoToMsgSource(user.Client),
 		ShowClientIcon:  true,
 		InvolvedUsers:   involvedUsers,
+		ReplyTo:         replyTo,
 	})
 }
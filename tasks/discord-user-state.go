@@ -0,0 +1,44 @@
+package tasks
+
+import (
+	"retro-chat-rooms/chat"
+	"time"
+)
+
+// DiscordUserState lets Discord users go through the same validations
+// as everyone else, it only lives for one message.
+type DiscordUserState struct {
+	user                chat.ChatUser
+	coolDownMessageSent bool
+	lastScream          time.Time
+}
+
+func NewDiscordUserState(user chat.ChatUser) DiscordUserState {
+	return DiscordUserState{user: user, lastScream: time.Unix(0, 0).UTC()}
+}
+
+func (dus *DiscordUserState) GetUserID() string {
+	return dus.user.ID
+}
+
+func (dus *DiscordUserState) GetCoolDownMessageSent() bool {
+	return dus.coolDownMessageSent
+}
+
+func (dus *DiscordUserState) SetCoolDownMessageSent(v bool) {
+	dus.coolDownMessageSent = v
+}
+
+func (dus *DiscordUserState) GetLastScream() time.Time {
+	return dus.lastScream
+}
+
+func (dus *DiscordUserState) SetLastScream(t time.Time) {
+	dus.lastScream = t
+}
+
+// GetUserIP has no IP to give, flood control counts each Discord user
+// on their own instead.
+func (dus *DiscordUserState) GetUserIP() string {
+	return "discord:" + dus.user.DiscordId
+}
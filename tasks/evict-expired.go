@@ -0,0 +1,21 @@
+package tasks
+
+import (
+	"retro-chat-rooms/floodcontrol"
+	"retro-chat-rooms/lockout"
+	"retro-chat-rooms/roles"
+	"retro-chat-rooms/spam"
+	"time"
+)
+
+// EvictExpired keeps flood control, login lockouts, the spam filter and
+// staff logins from remembering every IP and session that ever came by.
+func EvictExpired() {
+	for {
+		time.Sleep(floodcontrol.EVICTION_CHECK_SEC * time.Second)
+		floodcontrol.EvictIdle()
+		lockout.EvictExpired()
+		spam.EvictIdle()
+		roles.EvictSessions()
+	}
+}
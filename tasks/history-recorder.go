@@ -0,0 +1,35 @@
+package tasks
+
+import (
+	"log"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/history"
+	"retro-chat-rooms/pubsub"
+)
+
+func recordRoomMessages(events pubsub.Pubsub) {
+	c := events.Subscribe("history-recorder")
+	for message := range c {
+		switch evt := message.(type) {
+		case chat.ChatMessageEvent:
+			// Shadowed messages were never seen by the room
+			if evt.Message == nil || evt.Message.Shadowed {
+				continue
+			}
+
+			if err := history.Save(evt.Message); err != nil {
+				log.Printf("Error saving message history: %v", err)
+			}
+		case chat.ChatMessagesPurgedEvent:
+			if err := history.Remove(evt.RoomID, evt.MessageIDs, evt.Since); err != nil {
+				log.Printf("Error removing purged messages from history: %v", err)
+			}
+		}
+	}
+}
+
+func ObserveMessagesToHistory() {
+	for _, events := range chat.RoomEvents {
+		go recordRoomMessages(events)
+	}
+}
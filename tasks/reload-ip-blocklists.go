@@ -0,0 +1,18 @@
+package tasks
+
+import (
+	"log"
+	"retro-chat-rooms/netblocks"
+	"time"
+)
+
+// ReloadIPBlocklists picks up edits to the blocklist files without a restart.
+func ReloadIPBlocklists() {
+	for {
+		for _, file := range netblocks.ReloadChanged() {
+			log.Printf("Loaded the IP blocklist %s", file)
+		}
+
+		time.Sleep(netblocks.RELOAD_CHECK_SEC * time.Second)
+	}
+}
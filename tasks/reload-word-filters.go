@@ -0,0 +1,18 @@
+package tasks
+
+import (
+	"log"
+	"retro-chat-rooms/profanity"
+	"time"
+)
+
+// ReloadWordFilters picks up edits to the word lists without a restart.
+func ReloadWordFilters() {
+	for {
+		time.Sleep(profanity.RELOAD_CHECK_SEC * time.Second)
+
+		for _, list := range profanity.ReloadChanged() {
+			log.Printf("Reloaded the %s words", list)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package tasks
+
+import (
+	"fmt"
+	"retro-chat-rooms/config"
+	"retro-chat-rooms/discord"
+	"retro-chat-rooms/reports"
+	"strings"
+)
+
+// MirrorReportsToDiscord lets moderators on Discord know about new reports.
+func MirrorReportsToDiscord() {
+	if config.Current.DiscordModerationChannel == "" {
+		return
+	}
+
+	c := reports.Events.Subscribe("discord-bot")
+	for message := range c {
+		if evt, ok := message.(reports.ReportSubmittedEvent); ok {
+			r := evt.Report
+			discord.Instance.SendSystemMessage(config.Current.DiscordModerationChannel, fmt.Sprintf(
+				"**Report** in %s by %s: %s said `%s` (%s)",
+				r.RoomID, r.Reporter, r.Author, strings.ReplaceAll(r.Message.Message, "`", "'"), r.Reason,
+			))
+		}
+	}
+}
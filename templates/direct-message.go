@@ -0,0 +1,46 @@
+package templates
+
+import (
+	"html/template"
+	"retro-chat-rooms/chat"
+	"retro-chat-rooms/helpers"
+	"retro-chat-rooms/routes"
+	"strings"
+)
+
+func writeRoomName(b *strings.Builder, roomId string) {
+	room, found := chat.GetSingleRoom(roomId)
+	if !found {
+		return
+	}
+
+	b.WriteString(`<font size="-1">(`)
+	b.WriteString(room.Name)
+	b.WriteString(")</font>")
+}
+
+func RenderDirectMessage(userId string, message *chat.DirectMessage) template.HTML {
+	var buffer strings.Builder
+
+	buffer.WriteString("[")
+	buffer.WriteString(helpers.FormatTimestamp(message.Time))
+	buffer.WriteString("] ")
+
+	writeNickname(&buffer, &message.From)
+	writeRoomName(&buffer, message.From.RoomId)
+	buffer.WriteString(" to ")
+	writeNickname(&buffer, &message.To)
+	writeRoomName(&buffer, message.To.RoomId)
+	buffer.WriteString(": ")
+	buffer.WriteString(message.Message)
+
+	if message.From.ID != userId {
+		buffer.WriteString(` <font size="-1">[<a href="`)
+		buffer.WriteString(routes.UrlPrivateTalk(message.To.RoomId, message.From.ID))
+		buffer.WriteString(`" target="privatetalk">reply</a>]</font>`)
+	}
+
+	buffer.WriteString("<br>")
+
+	return template.HTML(buffer.String())
+}
@@ -4,6 +4,7 @@ import (
 	"html/template"
 	"retro-chat-rooms/chat"
 	"retro-chat-rooms/helpers"
+	"retro-chat-rooms/routes"
 	"strings"
 )
 
@@ -53,9 +54,33 @@ func writeUserMessageDescriptor(b *strings.Builder, speechMode string, message *
 	messageRendered()
 }
 
+func writeQuote(b *strings.Builder, quote *chat.QuotedMessage) {
+	b.WriteString(`<font size="-1" color="#808080">&nbsp;&nbsp;&raquo;&nbsp;<strong>`)
+	b.WriteString(quote.Nickname)
+	b.WriteString("</strong>: <i>")
+	b.WriteString(template.HTMLEscapeString(quote.Excerpt))
+	b.WriteString("</i></font><br>")
+}
+
+func writeReplyLink(b *strings.Builder, message *chat.ChatMessage) {
+	b.WriteString(` <a href="`)
+	b.WriteString(routes.UrlChatReply(message.RoomID, message.From, message.ID))
+	b.WriteString(`" target="talk"><font size="-2">[reply]</font></a>`)
+}
+
+func writeReportLink(b *strings.Builder, message *chat.ChatMessage) {
+	b.WriteString(` <a href="`)
+	b.WriteString(routes.UrlChatReport(message.RoomID, message.ID))
+	b.WriteString(`" target="talk"><font size="-2">[report]</font></a>`)
+}
+
 func RenderMessage(userId string, message *chat.ChatMessage) template.HTML {
 	var buffer strings.Builder
 
+	if message.ReplyTo != nil {
+		writeQuote(&buffer, message.ReplyTo)
+	}
+
 	buffer.WriteString("[")
 	buffer.WriteString(helpers.FormatTimestamp(message.Time))
 	buffer.WriteString("] ")
@@ -79,6 +104,17 @@ func RenderMessage(userId string, message *chat.ChatMessage) template.HTML {
 				buffer.WriteString("</i></font>")
 			})
 		}
+
+		writeReplyLink(&buffer, message)
+
+		if message.From != userId {
+			writeReportLink(&buffer, message)
+		}
+
+		// Only moderators get someone else's shadowed messages
+		if message.Shadowed && message.From != userId {
+			buffer.WriteString(` <font size="-2" color="#808080">[shadowed]</font>`)
+		}
 	} else {
 		writeMessage(&buffer, message)
 	}
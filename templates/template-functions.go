@@ -14,6 +14,10 @@ func countUsers(roomId string) int {
 	return len(chat.GetRoomOnlineUsers(roomId))
 }
 
+func inc(i int) int {
+	return i + 1
+}
+
 func hasStrings(input []string) bool {
 	return len(input) > 0
 }
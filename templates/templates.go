@@ -42,21 +42,30 @@ func getAllTemplates() map[string]string {
 
 func LoadTemplates(router *gin.Engine) {
 	funcMap := template.FuncMap{
-		"renderMessage":  RenderMessage,
-		"renderUsername": RenderUsername,
-		"formatTime":     formatTime,
-		"countUsers":     countUsers,
-		"hasStrings":     hasStrings,
-		"bustCache":      routes.BustCache,
-		"urlRoom":        routes.UrlRoom,
-		"urlJoin":        routes.UrlJoin,
-		"urlLogout":      routes.UrlLogout,
-		"urlCaptcha":     routes.UrlCaptcha,
-		"urlChatHeader":  routes.UrlChatHeader,
-		"urlChatThread":  routes.UrlChatThread,
-		"urlChatUpdater": routes.UrlChatUpdater,
-		"urlChatTalk":    routes.UrlChatTalk,
-		"urlChatUsers":   routes.UrlChatUsers,
+		"renderMessage":       RenderMessage,
+		"renderUsername":      RenderUsername,
+		"formatTime":          formatTime,
+		"countUsers":          countUsers,
+		"hasStrings":          hasStrings,
+		"bustCache":           routes.BustCache,
+		"urlRoom":             routes.UrlRoom,
+		"urlJoin":             routes.UrlJoin,
+		"urlLogout":           routes.UrlLogout,
+		"urlCaptcha":          routes.UrlCaptcha,
+		"urlChatHeader":       routes.UrlChatHeader,
+		"urlChatThread":       routes.UrlChatThread,
+		"urlChatUpdater":      routes.UrlChatUpdater,
+		"urlChatTalk":         routes.UrlChatTalk,
+		"urlChatUsers":        routes.UrlChatUsers,
+		"urlVote":             routes.UrlVote,
+		"urlKicked":           routes.UrlKicked,
+		"urlChatModerate":     routes.UrlChatModerate,
+		"urlChatReport":       routes.UrlChatReport,
+		"urlPrivate":          routes.UrlPrivate,
+		"urlPrivateThread":    routes.UrlPrivateThread,
+		"urlPrivateTalk":      routes.UrlPrivateTalk,
+		"renderDirectMessage": RenderDirectMessage,
+		"inc":                 inc,
 	}
 
 	templates := getAllTemplates()
@@ -3,6 +3,7 @@ package templates
 import (
 	"html/template"
 	"retro-chat-rooms/chat"
+	"retro-chat-rooms/roles"
 	"retro-chat-rooms/routes"
 	"strings"
 )
@@ -27,6 +28,17 @@ func writeNickname(b *strings.Builder, user *chat.ChatUser) {
 	b.WriteString("</strong> ")
 }
 
+func writeRoleBadge(b *strings.Builder, role string) {
+	badge := roles.Badge(role)
+	if badge == "" {
+		return
+	}
+
+	b.WriteString(`<font size="-2" color="#990000">[`)
+	b.WriteString(badge)
+	b.WriteString("]</font> ")
+}
+
 func wrapNicknameWithLink(b *strings.Builder, user *chat.ChatUser) string {
 	var buffer strings.Builder
 
@@ -43,6 +55,7 @@ func RenderUsername(userId string, user *chat.ChatUser) template.HTML {
 	var buffer strings.Builder
 
 	writeNickname(&buffer, user)
+	writeRoleBadge(&buffer, user.Role)
 
 	if userId != user.ID {
 		return template.HTML(wrapNicknameWithLink(&buffer, user))